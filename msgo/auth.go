@@ -38,7 +38,7 @@ func (a *Accounts) unAuthHandler(ctx *Context) {
 		a.UnAuthHandler(ctx)
 	} else {
 		ctx.W.Header().Set("WWW-Authenticate", a.Realm)
-		ctx.W.WriteHeader(http.StatusUnauthorized)
+		ctx.AbortWithStatus(http.StatusUnauthorized)
 	}
 }
 
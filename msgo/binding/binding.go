@@ -2,12 +2,57 @@ package binding
 
 import "net/http"
 
+const (
+	MIMEJSON              = "application/json"
+	MIMEHTML              = "text/html"
+	MIMEXML               = "application/xml"
+	MIMEXML2              = "text/xml"
+	MIMEPlain             = "text/plain"
+	MIMEPOSTForm          = "application/x-www-form-urlencoded"
+	MIMEMultipartPOSTForm = "multipart/form-data"
+)
+
 type Binding interface {
 	Name() string
 	Bind(*http.Request, any) error
 }
 
+//BindingBody 从已经读取的请求体中绑定 用于请求体需要多次读取的场景
+type BindingBody interface {
+	Binding
+	BindBody([]byte, any) error
+}
+
+//BindingUri 路径参数不在请求里 单独绑定
+type BindingUri interface {
+	Name() string
+	BindUri(map[string][]string, any) error
+}
+
 var (
-	JSON = jsonBinding{}
-	XML  = xmlBinding{}
+	JSON          = jsonBinding{}
+	XML           = xmlBinding{}
+	Query         = queryBinding{}
+	Form          = formBinding{}
+	FormPost      = formPostBinding{}
+	FormMultipart = formMultipartBinding{}
+	Header        = headerBinding{}
+	Uri           = uriBinding{}
 )
+
+//Default 根据请求方式和Content-Type选择绑定器 GET请求绑定查询参数
+func Default(method, contentType string) Binding {
+	if method == http.MethodGet {
+		return Form
+	}
+	switch contentType {
+	case MIMEJSON:
+		return JSON
+	case MIMEXML, MIMEXML2:
+		return XML
+	case MIMEMultipartPOSTForm:
+		return FormMultipart
+	default:
+		return Form
+	}
+}
@@ -0,0 +1,155 @@
+package binding
+
+import (
+	"errors"
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//FieldError 单个字段的校验错误 Field 是json中的路径 如 items[2].sku
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+type FieldErrors []FieldError
+
+func (errs FieldErrors) Error() string {
+	s := make([]string, len(errs))
+	for i, err := range errs {
+		s[i] = err.Message
+	}
+	return strings.Join(s, "\n")
+}
+
+//uni 默认支持英文和中文 找不到对应语言时使用英文
+var uni = ut.New(en.New(), en.New(), zh.New())
+
+type registerTranslationsFunc func(v *validator.Validate, trans ut.Translator) error
+
+var translations = map[string]registerTranslationsFunc{
+	"en": enTranslations.RegisterDefaultTranslations,
+	"zh": zhTranslations.RegisterDefaultTranslations,
+}
+
+//AddTranslation 增加一种语言 需要在第一次校验之前调用
+func AddTranslation(l locales.Translator, register func(v *validator.Validate, trans ut.Translator) error) error {
+	if err := uni.AddTranslator(l, true); err != nil {
+		return err
+	}
+	translations[l.Locale()] = register
+	return nil
+}
+
+func registerTranslations(v *validator.Validate) {
+	for locale, register := range translations {
+		trans, _ := uni.GetTranslator(locale)
+		_ = register(v, trans)
+	}
+}
+
+//Translator 按顺序查找语言 如 zh_CN zh en
+func Translator(locales ...string) ut.Translator {
+	trans, _ := uni.FindTranslator(locales...)
+	return trans
+}
+
+//ToFieldErrors 把校验错误转换为 FieldErrors 其它错误返回false
+func ToFieldErrors(err error, locales ...string) (FieldErrors, bool) {
+	validationErrs, ok := toValidationErrors(err)
+	if !ok {
+		return nil, false
+	}
+	trans := Translator(locales...)
+	result := make(FieldErrors, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		result = append(result, FieldError{
+			Field:   fieldPath(fe),
+			Tag:     fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Translate(trans),
+		})
+	}
+	return result, true
+}
+
+//ValidationErrors 绑定json时 msgo:"required" 的检查和 Validator 的错误合在一起
+//validator.ValidationErrors 只能放它自己的错误类型 所以单独定义
+type ValidationErrors []validator.FieldError
+
+func (errs ValidationErrors) Error() string {
+	s := make([]string, len(errs))
+	for i, err := range errs {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+//toValidationErrors 统一成 ValidationErrors 切片的校验错误展开 字段路径加上下标
+func toValidationErrors(err error) (ValidationErrors, bool) {
+	var errs ValidationErrors
+	if errors.As(err, &errs) {
+		return errs, true
+	}
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return ValidationErrors(validationErrs), true
+	}
+	var sliceErrs SliceValidationError
+	if !errors.As(err, &sliceErrs) {
+		return nil, false
+	}
+	for i, e := range sliceErrs {
+		var ve validator.ValidationErrors
+		if errors.As(e, &ve) {
+			for _, fe := range ve {
+				errs = append(errs, &indexedFieldError{FieldError: fe, index: i})
+			}
+		}
+	}
+	return errs, len(errs) > 0
+}
+
+//fieldPath 错误字段的json路径 Namespace 第一段是结构体的名字 去掉
+func fieldPath(fe validator.FieldError) string {
+	if p, ok := fe.(interface{ path() string }); ok {
+		return p.path()
+	}
+	field := fe.Namespace()
+	if _, rest, ok := strings.Cut(field, "."); ok {
+		field = rest
+	}
+	return field
+}
+
+//indexedFieldError 绑定的是切片时 第几个元素的错误
+type indexedFieldError struct {
+	validator.FieldError
+	index int
+}
+
+func (e *indexedFieldError) path() string {
+	return "[" + strconv.Itoa(e.index) + "]." + fieldPath(e.FieldError)
+}
+
+//jsonTagName 校验错误中使用json名字代替结构体字段名
+func jsonTagName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,333 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errUnknownType = errors.New("unknown type")
+	errNotPointer  = errors.New("this argument must have a pointer type")
+)
+
+//setter 不同来源(查询参数 表单 header 路径参数)的取值方式
+type setter interface {
+	TrySet(value reflect.Value, field reflect.StructField, key string, opt setOptions) (bool, error)
+}
+
+type setOptions struct {
+	isDefaultExists bool
+	defaultValue    string
+}
+
+type formSource map[string][]string
+
+func (form formSource) TrySet(value reflect.Value, field reflect.StructField, key string, opt setOptions) (bool, error) {
+	return setByForm(value, field, form, key, opt)
+}
+
+func mapForm(ptr any, form map[string][]string) error {
+	return mapFormByTag(ptr, form, "form")
+}
+
+func mapFormByTag(ptr any, form map[string][]string, tag string) error {
+	return mappingByPtr(ptr, formSource(form), tag)
+}
+
+func mappingByPtr(ptr any, s setter, tag string) error {
+	value := reflect.ValueOf(ptr)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return errNotPointer
+	}
+	_, err := mapping(value, reflect.StructField{Anonymous: true}, s, tag)
+	return err
+}
+
+//mapping 递归处理结构体字段 未写标签的嵌套结构体也会处理
+func mapping(value reflect.Value, field reflect.StructField, s setter, tag string) (bool, error) {
+	if field.Tag.Get(tag) == "-" {
+		return false, nil
+	}
+	vKind := value.Kind()
+	if vKind == reflect.Pointer {
+		var isNew bool
+		vPtr := value
+		if value.IsNil() {
+			isNew = true
+			vPtr = reflect.New(value.Type().Elem())
+		}
+		isSet, err := mapping(vPtr.Elem(), field, s, tag)
+		if err != nil {
+			return false, err
+		}
+		if isNew && isSet {
+			value.Set(vPtr)
+		}
+		return isSet, nil
+	}
+	if vKind != reflect.Struct || !field.Anonymous {
+		ok, err := tryToSetValue(value, field, s, tag)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	if vKind == reflect.Struct {
+		tValue := value.Type()
+		var isSet bool
+		for i := 0; i < value.NumField(); i++ {
+			sf := tValue.Field(i)
+			if sf.PkgPath != "" && !sf.Anonymous {
+				//未导出的字段
+				continue
+			}
+			ok, err := mapping(value.Field(i), sf, s, tag)
+			if err != nil {
+				return false, err
+			}
+			isSet = isSet || ok
+		}
+		return isSet, nil
+	}
+	return false, nil
+}
+
+//tryToSetValue 解析标签 form:"name,default=1"
+func tryToSetValue(value reflect.Value, field reflect.StructField, s setter, tag string) (bool, error) {
+	var opt setOptions
+	tagValue := field.Tag.Get(tag)
+	name, opts, _ := strings.Cut(tagValue, ",")
+	if name == "" {
+		name = field.Name
+	}
+	if name == "" {
+		return false, nil
+	}
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if k, v, _ := strings.Cut(o, "="); k == "default" {
+			opt.isDefaultExists = true
+			opt.defaultValue = v
+		}
+	}
+	return s.TrySet(value, field, name, opt)
+}
+
+func setByForm(value reflect.Value, field reflect.StructField, form map[string][]string, key string, opt setOptions) (bool, error) {
+	vs, ok := form[key]
+	if !ok && !opt.isDefaultExists {
+		return false, nil
+	}
+	switch value.Kind() {
+	case reflect.Slice:
+		if !ok {
+			vs = []string{opt.defaultValue}
+		}
+		return true, setSlice(vs, value, field)
+	case reflect.Array:
+		if !ok {
+			vs = []string{opt.defaultValue}
+		}
+		if len(vs) != value.Len() {
+			return false, fmt.Errorf("%q is not valid value for %s", vs, value.Type().String())
+		}
+		return true, setArray(vs, value, field)
+	default:
+		var val string
+		if !ok {
+			val = opt.defaultValue
+		}
+		if len(vs) > 0 {
+			val = vs[0]
+		}
+		return true, setWithProperType(val, value, field)
+	}
+}
+
+func setWithProperType(val string, value reflect.Value, field reflect.StructField) error {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return setIntField(val, value.Type().Bits(), value)
+	case reflect.Int64:
+		if _, ok := value.Interface().(time.Duration); ok {
+			return setTimeDuration(val, value)
+		}
+		return setIntField(val, 64, value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return setUintField(val, value.Type().Bits(), value)
+	case reflect.Bool:
+		return setBoolField(val, value)
+	case reflect.Float32, reflect.Float64:
+		return setFloatField(val, value.Type().Bits(), value)
+	case reflect.String:
+		value.SetString(val)
+	case reflect.Struct:
+		if _, ok := value.Interface().(time.Time); ok {
+			return setTimeField(val, field, value)
+		}
+		return errUnknownType
+	case reflect.Pointer:
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return setWithProperType(val, value.Elem(), field)
+	default:
+		return errUnknownType
+	}
+	return nil
+}
+
+func setIntField(val string, bitSize int, field reflect.Value) error {
+	if val == "" {
+		val = "0"
+	}
+	intVal, err := strconv.ParseInt(val, 10, bitSize)
+	if err == nil {
+		field.SetInt(intVal)
+	}
+	return err
+}
+
+func setUintField(val string, bitSize int, field reflect.Value) error {
+	if val == "" {
+		val = "0"
+	}
+	uintVal, err := strconv.ParseUint(val, 10, bitSize)
+	if err == nil {
+		field.SetUint(uintVal)
+	}
+	return err
+}
+
+func setBoolField(val string, field reflect.Value) error {
+	if val == "" {
+		val = "false"
+	}
+	boolVal, err := strconv.ParseBool(val)
+	if err == nil {
+		field.SetBool(boolVal)
+	}
+	return err
+}
+
+func setFloatField(val string, bitSize int, field reflect.Value) error {
+	if val == "" {
+		val = "0.0"
+	}
+	floatVal, err := strconv.ParseFloat(val, bitSize)
+	if err == nil {
+		field.SetFloat(floatVal)
+	}
+	return err
+}
+
+//setTimeField 默认RFC3339 time_format:"unix" 表示时间戳 time_utc:"1" 使用UTC
+func setTimeField(val string, structField reflect.StructField, value reflect.Value) error {
+	if val == "" {
+		value.Set(reflect.ValueOf(time.Time{}))
+		return nil
+	}
+	timeFormat := structField.Tag.Get("time_format")
+	if timeFormat == "" {
+		timeFormat = time.RFC3339
+	}
+	switch tf := strings.ToLower(timeFormat); tf {
+	case "unix", "unixnano":
+		tv, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return err
+		}
+		d := time.Duration(1)
+		if tf == "unixnano" {
+			d = time.Second
+		}
+		t := time.Unix(tv/int64(d), tv%int64(d))
+		value.Set(reflect.ValueOf(t))
+		return nil
+	}
+	l := time.Local
+	if isUTC, _ := strconv.ParseBool(structField.Tag.Get("time_utc")); isUTC {
+		l = time.UTC
+	}
+	t, err := time.ParseInLocation(timeFormat, val, l)
+	if err != nil {
+		return err
+	}
+	value.Set(reflect.ValueOf(t))
+	return nil
+}
+
+func setTimeDuration(val string, value reflect.Value) error {
+	if val == "" {
+		val = "0"
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return err
+	}
+	value.Set(reflect.ValueOf(d))
+	return nil
+}
+
+func setArray(vals []string, value reflect.Value, field reflect.StructField) error {
+	for i, s := range vals {
+		if err := setWithProperType(s, value.Index(i), field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setSlice(vals []string, value reflect.Value, field reflect.StructField) error {
+	slice := reflect.MakeSlice(value.Type(), len(vals), len(vals))
+	if err := setArray(vals, slice, field); err != nil {
+		return err
+	}
+	value.Set(slice)
+	return nil
+}
+
+//multipartRequest 表单和上传文件一起绑定
+type multipartRequest http.Request
+
+var (
+	multipartFileHeaderType    = reflect.TypeOf(multipart.FileHeader{})
+	multipartFileHeaderPtrType = reflect.TypeOf(&multipart.FileHeader{})
+)
+
+func (r *multipartRequest) TrySet(value reflect.Value, field reflect.StructField, key string, opt setOptions) (bool, error) {
+	if files := r.MultipartForm.File[key]; len(files) != 0 {
+		return setByMultipartFiles(value, field, files)
+	}
+	return setByForm(value, field, r.MultipartForm.Value, key, opt)
+}
+
+func setByMultipartFiles(value reflect.Value, field reflect.StructField, files []*multipart.FileHeader) (bool, error) {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.Type().Elem() == multipartFileHeaderType {
+			value.Set(reflect.ValueOf(files[0]))
+			return true, nil
+		}
+	case reflect.Struct:
+		if value.Type() == multipartFileHeaderType {
+			value.Set(reflect.ValueOf(*files[0]))
+			return true, nil
+		}
+	case reflect.Slice:
+		if value.Type().Elem() == multipartFileHeaderPtrType {
+			value.Set(reflect.ValueOf(files))
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("unsupported field type for multipart.FileHeader: %s", field.Name)
+}
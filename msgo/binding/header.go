@@ -0,0 +1,27 @@
+package binding
+
+import (
+	"net/http"
+	"net/textproto"
+	"reflect"
+)
+
+type headerBinding struct{}
+
+func (headerBinding) Name() string {
+	return "header"
+}
+
+//Bind 按 header 标签绑定 标签名不区分大小写
+func (headerBinding) Bind(r *http.Request, obj any) error {
+	if err := mappingByPtr(obj, headerSource(r.Header), "header"); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+type headerSource map[string][]string
+
+func (h headerSource) TrySet(value reflect.Value, field reflect.StructField, key string, opt setOptions) (bool, error) {
+	return setByForm(value, field, h, textproto.CanonicalMIMEHeaderKey(key), opt)
+}
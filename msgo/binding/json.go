@@ -1,11 +1,11 @@
 package binding
 
 import (
-	"encoding/json"
+	"bytes"
 	"errors"
-	"fmt"
+	"github.com/mszlu521/msgo/codec"
+	"io"
 	"net/http"
-	"reflect"
 )
 
 type jsonBinding struct {
@@ -23,91 +23,39 @@ func (b jsonBinding) Bind(r *http.Request, obj any) error {
 	if body == nil {
 		return errors.New("invalid request")
 	}
-	decoder := json.NewDecoder(body)
-	if b.DisallowUnknownFields {
-		decoder.DisallowUnknownFields()
-	}
 	if b.IsValidate {
-		err := validateParam(obj, decoder)
-		if err != nil {
-			return err
-		}
-	} else {
-		err := decoder.Decode(obj)
+		//检查必填字段需要原始的json
+		data, err := io.ReadAll(body)
 		if err != nil {
 			return err
 		}
+		return b.decodeJSON(data, obj)
+	}
+	//不需要检查必填字段时 边读边解析
+	if err := b.decode(body, obj); err != nil {
+		return err
 	}
 	return validate(obj)
 }
 
-func validateParam(obj any, decoder *json.Decoder) error {
-
-	//反射
-	valueOf := reflect.ValueOf(obj)
-	//判断其是否为指针类型
-	if valueOf.Kind() != reflect.Pointer {
-		return errors.New("This argument must have a pointer type")
-	}
-	elem := valueOf.Elem().Interface()
-	of := reflect.ValueOf(elem)
-
-	switch of.Kind() {
-	case reflect.Struct:
-		return checkParam(of, obj, decoder)
-	case reflect.Slice, reflect.Array:
-		elem := of.Type().Elem()
-		if elem.Kind() == reflect.Struct {
-			return checkParamSlice(elem, obj, decoder)
-		}
-	default:
-		_ = decoder.Decode(obj)
-	}
-	return nil
+func (b jsonBinding) BindBody(body []byte, obj any) error {
+	return b.decodeJSON(body, obj)
 }
 
-func checkParamSlice(of reflect.Type, obj any, decoder *json.Decoder) error {
-	mapValue := make([]map[string]interface{}, 0)
-	_ = decoder.Decode(&mapValue)
-	for i := 0; i < of.NumField(); i++ {
-		field := of.Field(i)
-		name := field.Name
-		jsonName := field.Tag.Get("json")
-		if jsonName != "" {
-			name = jsonName
-		}
-		required := field.Tag.Get("msgo")
-		for _, v := range mapValue {
-			value := v[name]
-			if value == nil && required == "required" {
-				return errors.New(fmt.Sprintf("filed [%s] is not exist,because [%s] is required", jsonName, jsonName))
-			}
-		}
+func (b jsonBinding) decodeJSON(data []byte, obj any) error {
+	if err := b.decode(bytes.NewReader(data), obj); err != nil {
+		return err
+	}
+	if b.IsValidate {
+		return validateJSON(obj, data)
 	}
-	b, _ := json.Marshal(mapValue)
-	_ = json.Unmarshal(b, obj)
-	return nil
+	return validate(obj)
 }
 
-func checkParam(of reflect.Value, obj any, decoder *json.Decoder) error {
-	//解析为map，然后根据map中的key 进行比对
-	//判断类型 结构体 才能解析为map
-	mapValue := make(map[string]interface{})
-	_ = decoder.Decode(&mapValue)
-	for i := 0; i < of.NumField(); i++ {
-		field := of.Type().Field(i)
-		name := field.Name
-		jsonName := field.Tag.Get("json")
-		if jsonName != "" {
-			name = jsonName
-		}
-		required := field.Tag.Get("msgo")
-		value := mapValue[name]
-		if value == nil && required == "required" {
-			return errors.New(fmt.Sprintf("filed [%s] is not exist,because [%s] is required", jsonName, jsonName))
-		}
+func (b jsonBinding) decode(r io.Reader, obj any) error {
+	decoder := codec.JSON.NewDecoder(r)
+	if b.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
 	}
-	b, _ := json.Marshal(mapValue)
-	_ = json.Unmarshal(b, obj)
-	return nil
+	return decoder.Decode(obj)
 }
@@ -0,0 +1,240 @@
+package binding
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/mszlu521/msgo/codec"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//RequiredError 带 msgo:"required" 标签的字段在json中不存在或者为null
+//实现了 validator.FieldError 和 Validator 的错误合并在一个 ValidationErrors 中返回
+type RequiredError struct {
+	root        string
+	fieldPath   string //完整的json路径 如 items[2].sku
+	field       string
+	structField string
+	typ         reflect.Type
+}
+
+var _ validator.FieldError = (*RequiredError)(nil)
+
+func (e *RequiredError) Tag() string {
+	return "required"
+}
+
+func (e *RequiredError) ActualTag() string {
+	return "required"
+}
+
+func (e *RequiredError) Namespace() string {
+	if strings.HasPrefix(e.fieldPath, "[") {
+		return e.root + e.fieldPath
+	}
+	return e.root + "." + e.fieldPath
+}
+
+func (e *RequiredError) StructNamespace() string {
+	return e.Namespace()
+}
+
+func (e *RequiredError) Field() string {
+	return e.field
+}
+
+func (e *RequiredError) StructField() string {
+	return e.structField
+}
+
+func (e *RequiredError) Value() any {
+	return nil
+}
+
+func (e *RequiredError) Param() string {
+	return ""
+}
+
+func (e *RequiredError) Kind() reflect.Kind {
+	return e.typ.Kind()
+}
+
+func (e *RequiredError) Type() reflect.Type {
+	return e.typ
+}
+
+//Translate 和 Validator 的 required 用同一个翻译
+func (e *RequiredError) Translate(trans ut.Translator) string {
+	msg, err := trans.T("required", e.field)
+	if err != nil {
+		return e.Error()
+	}
+	return msg
+}
+
+func (e *RequiredError) Error() string {
+	return fmt.Sprintf("field [%s] is not exist,because [%s] is required", e.fieldPath, e.fieldPath)
+}
+
+func (e *RequiredError) path() string {
+	return e.fieldPath
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+//validateJSON 必填检查和 Validator 都执行 错误合并成一个 ValidationErrors
+func validateJSON(obj any, data []byte) error {
+	var raw any
+	if err := codec.JSON.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	t := reflect.TypeOf(obj)
+	checker := &requiredChecker{root: indirectType(t).Name()}
+	checker.check(t, raw, "")
+	err := validate(obj)
+	if len(checker.errs) == 0 {
+		return err
+	}
+	if err == nil {
+		return checker.errs
+	}
+	validationErrs, ok := toValidationErrors(err)
+	if !ok {
+		//自定义的 Validator 返回了其它错误 没法合并
+		return err
+	}
+	return mergeRequired(checker.errs, validationErrs)
+}
+
+//mergeRequired 同一个字段 Validator 也报了 required 的只保留一个
+func mergeRequired(required, errs ValidationErrors) ValidationErrors {
+	missing := make(map[string]bool, len(required))
+	for _, fe := range required {
+		missing[fieldPath(fe)] = true
+	}
+	for _, fe := range errs {
+		if fe.Tag() == "required" && missing[fieldPath(fe)] {
+			continue
+		}
+		required = append(required, fe)
+	}
+	return required
+}
+
+type requiredChecker struct {
+	root string
+	errs ValidationErrors
+}
+
+//check 按照类型递归检查json 支持嵌套结构体 指针 切片 map
+func (c *requiredChecker) check(t reflect.Type, raw any, path string) {
+	t = indirectType(t)
+	if raw == nil || customUnmarshal(t) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := raw.(map[string]any)
+		if !ok {
+			return
+		}
+		c.checkStruct(t, m, path)
+	case reflect.Slice, reflect.Array:
+		list, ok := raw.([]any)
+		if !ok {
+			return
+		}
+		for i, v := range list {
+			c.check(t.Elem(), v, path+"["+strconv.Itoa(i)+"]")
+		}
+	case reflect.Map:
+		m, ok := raw.(map[string]any)
+		if !ok {
+			return
+		}
+		for k, v := range m {
+			c.check(t.Elem(), v, joinPath(path, k))
+		}
+	}
+}
+
+func (c *requiredChecker) checkStruct(t reflect.Type, m map[string]any, path string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			//匿名嵌入的结构体 字段是平铺的
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				c.checkStruct(ft, m, path)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		value, ok := lookupKey(m, name)
+		if !ok || value == nil {
+			if field.Tag.Get("msgo") == "required" {
+				c.errs = append(c.errs, &RequiredError{
+					root:        c.root,
+					fieldPath:   joinPath(path, name),
+					field:       name,
+					structField: field.Name,
+					typ:         field.Type,
+				})
+			}
+			continue
+		}
+		c.check(field.Type, value, joinPath(path, name))
+	}
+}
+
+//lookupKey 和 encoding/json 一样 key 不区分大小写
+func lookupKey(m map[string]any, name string) (any, bool) {
+	if v, ok := m[name]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func customUnmarshal(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return pt.Implements(jsonUnmarshalerType) || pt.Implements(textUnmarshalerType)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,15 @@
+package binding
+
+type uriBinding struct{}
+
+func (uriBinding) Name() string {
+	return "uri"
+}
+
+//BindUri 绑定路径参数 /user/get/:id 对应 uri:"id"
+func (uriBinding) BindUri(m map[string][]string, obj any) error {
+	if err := mapFormByTag(obj, m, "uri"); err != nil {
+		return err
+	}
+	return validate(obj)
+}
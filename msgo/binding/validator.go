@@ -25,25 +25,17 @@ type defaultValidator struct {
 type SliceValidationError []error
 
 func (err SliceValidationError) Error() string {
-	n := len(err)
-	switch n {
-	case 0:
-		return ""
-	default:
-		var b strings.Builder
-		if err[0] != nil {
-			fmt.Fprintf(&b, "[%d]: %s", 0, err[0].Error())
+	var b strings.Builder
+	for i, e := range err {
+		if e == nil {
+			continue
 		}
-		if n > 1 {
-			for i := 1; i < n; i++ {
-				if err[i] != nil {
-					b.WriteString("\n")
-					fmt.Fprintf(&b, "[%d]: %s", i, err[i].Error())
-				}
-			}
+		if b.Len() > 0 {
+			b.WriteString("\n")
 		}
-		return b.String()
+		fmt.Fprintf(&b, "[%d]: %s", i, e.Error())
 	}
+	return b.String()
 }
 
 func (d *defaultValidator) ValidateStruct(obj any) error {
@@ -54,14 +46,17 @@ func (d *defaultValidator) ValidateStruct(obj any) error {
 	case reflect.Struct:
 		return d.validateStruct(obj)
 	case reflect.Slice, reflect.Array:
+		//通过的元素留nil 下标就是元素在切片中的位置
 		count := of.Len()
-		sliceValidationError := make(SliceValidationError, 0)
+		sliceValidationError := make(SliceValidationError, count)
+		failed := false
 		for i := 0; i < count; i++ {
 			if err := d.validateStruct(of.Index(i).Interface()); err != nil {
-				sliceValidationError = append(sliceValidationError, err)
+				sliceValidationError[i] = err
+				failed = true
 			}
 		}
-		if len(sliceValidationError) == 0 {
+		if !failed {
 			return nil
 		}
 		return sliceValidationError
@@ -77,6 +72,8 @@ func (d *defaultValidator) Engine() any {
 func (d *defaultValidator) lazyInit() {
 	d.one.Do(func() {
 		d.validate = validator.New()
+		d.validate.RegisterTagNameFunc(jsonTagName)
+		registerTranslations(d.validate)
 	})
 }
 
@@ -1,7 +1,9 @@
 package binding
 
 import (
+	"bytes"
 	"encoding/xml"
+	"io"
 	"net/http"
 )
 
@@ -16,7 +18,15 @@ func (b xmlBinding) Bind(r *http.Request, obj any) error {
 	if r.Body == nil {
 		return nil
 	}
-	decoder := xml.NewDecoder(r.Body)
+	return decodeXML(r.Body, obj)
+}
+
+func (b xmlBinding) BindBody(body []byte, obj any) error {
+	return decodeXML(bytes.NewReader(body), obj)
+}
+
+func decodeXML(r io.Reader, obj any) error {
+	decoder := xml.NewDecoder(r)
 	if err := decoder.Decode(obj); err != nil {
 		return err
 	}
@@ -0,0 +1,235 @@
+package msgo
+
+import (
+	"github.com/mszlu521/msgo/binding"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindQueryReq struct {
+	Name  string    `form:"name" validate:"required"`
+	Age   *int      `form:"age"`
+	Tags  []string  `form:"tag"`
+	Page  int       `form:"page,default=1"`
+	Since time.Time `form:"since" time_format:"2006-01-02" time_utc:"1"`
+	Skip  string    `form:"-"`
+}
+
+func TestContextBindQuery(t *testing.T) {
+	engine := New()
+	var req bindQueryReq
+	var bindErr error
+	engine.Group("user").Get("/find", func(ctx *Context) {
+		bindErr = ctx.Bind(&req)
+	})
+	performRequest(engine, http.MethodGet, "/user/find?name=ms&age=18&tag=a&tag=b&since=2022-07-01&Skip=x")
+	if bindErr != nil {
+		t.Fatal(bindErr)
+	}
+	if req.Name != "ms" || req.Age == nil || *req.Age != 18 || req.Page != 1 || req.Skip != "" {
+		t.Fatalf("unexpected result: %+v", req)
+	}
+	if len(req.Tags) != 2 || req.Tags[1] != "b" {
+		t.Fatalf("tags = %v", req.Tags)
+	}
+	if !req.Since.Equal(time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("since = %v", req.Since)
+	}
+
+	req = bindQueryReq{}
+	w := performRequest(engine, http.MethodGet, "/user/find?age=18")
+	if bindErr == nil || w.Code != http.StatusBadRequest {
+		t.Fatalf("missing required field: code %d, err %v", w.Code, bindErr)
+	}
+}
+
+func TestContextBindByContentType(t *testing.T) {
+	engine := New()
+	type formReq struct {
+		ID    int64  `form:"id" uri:"id"`
+		Token string `header:"x-token"`
+		Name  string `form:"name"`
+	}
+	var form, header, uri formReq
+	var errs []error
+	engine.Group("user").Post("/save/:id", func(ctx *Context) {
+		errs = append(errs, ctx.ShouldBind(&form), ctx.ShouldBindHeader(&header), ctx.ShouldBindUri(&uri))
+	})
+	r := httptest.NewRequest(http.MethodPost, "/user/save/7", strings.NewReader("id=3&name=ms"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	r.Header.Set("X-Token", "abc")
+	engine.ServeHTTP(httptest.NewRecorder(), r)
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if form.ID != 3 || form.Name != "ms" || header.Token != "abc" || uri.ID != 7 {
+		t.Fatalf("form %+v header %+v uri %+v", form, header, uri)
+	}
+}
+
+func TestBindJsonRequiredNested(t *testing.T) {
+	type item struct {
+		Sku   string `json:"sku" msgo:"required"`
+		Count int    `json:"count" validate:"lte=10"`
+	}
+	type order struct {
+		ID    int64            `json:"id" msgo:"required"`
+		Items []item           `json:"items" validate:"dive"`
+		Extra map[string]*item `json:"extra"`
+	}
+	engine := New()
+	var bindErr error
+	engine.Group("api").Post("/order", func(ctx *Context) {
+		var o order
+		bindErr = ctx.BindJson(&o)
+	})
+	post := func(body string) {
+		r := httptest.NewRequest(http.MethodPost, "/api/order", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		engine.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	//必填字段和 validate 标签的错误一起返回
+	post(`{"id":1,"items":[{"sku":"a"},{"sku":"b","count":20},{"count":2}],"extra":{"gift":{"count":1}}}`)
+	errs, ok := binding.ToFieldErrors(bindErr)
+	if !ok || len(errs) != 3 || errs[0].Field != "items[2].sku" || errs[1].Field != "extra.gift.sku" ||
+		errs[2].Field != "items[1].count" || errs[2].Tag != "lte" {
+		t.Fatalf("unexpected error: %v", bindErr)
+	}
+	post(`{"id":1,"items":[{"sku":"a"}]}`)
+	if bindErr != nil {
+		t.Fatal(bindErr)
+	}
+	post(`{"id":"x"}`)
+	if bindErr == nil {
+		t.Fatal("decode error should be returned")
+	}
+}
+
+func TestBindJsonSliceErrorIndex(t *testing.T) {
+	type item struct {
+		Sku string `json:"sku" msgo:"required"`
+		N   int    `json:"n" validate:"gte=1"`
+	}
+	engine := New()
+	var bindErr error
+	engine.Group("api").Post("/items", func(ctx *Context) {
+		var items []item
+		bindErr = ctx.BindJson(&items)
+	})
+	r := httptest.NewRequest(http.MethodPost, "/api/items", strings.NewReader(`[{"sku":"a","n":1},{"sku":"b","n":1},{"n":0}]`))
+	r.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(httptest.NewRecorder(), r)
+
+	//只有第三个元素校验失败 下标要和切片中的位置一致
+	errs, ok := binding.ToFieldErrors(bindErr)
+	if !ok || len(errs) != 2 || errs[0].Field != "[2].sku" || errs[1].Field != "[2].n" {
+		t.Fatalf("unexpected error: %v %v", bindErr, errs)
+	}
+}
+
+func TestContextBindError(t *testing.T) {
+	type user struct {
+		Name string `json:"name" validate:"required"`
+		Age  int    `json:"age" validate:"gte=18"`
+		Mail string `json:"mail" msgo:"required"`
+	}
+	engine := New()
+	engine.Group("api").Post("/user", func(ctx *Context) {
+		var u user
+		if err := ctx.BindJson(&u); err != nil {
+			_ = ctx.BindError(err)
+		}
+	})
+	post := func(body, lang string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/user", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept-Language", lang)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+
+	w := post(`{"mail":"a@b.c","age":10}`, "en-US,en;q=0.9")
+	want := `{"errors":[{"field":"name","tag":"required","message":"name is a required field"},` +
+		`{"field":"age","tag":"gte","param":"18","message":"age must be 18 or greater"}]}`
+	if w.Code != http.StatusBadRequest || strings.TrimSpace(w.Body.String()) != want {
+		t.Fatalf("%d %s", w.Code, w.Body.String())
+	}
+	w = post(`{"name":"ms","age":20}`, "zh-CN,zh;q=0.9")
+	want = `{"errors":[{"field":"mail","tag":"required","message":"mail为必填字段"}]}`
+	if w.Code != http.StatusBadRequest || strings.TrimSpace(w.Body.String()) != want {
+		t.Fatalf("%d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	engine := New()
+	engine.MaxBodyBytes = 16
+	var called int
+	handler := func(ctx *Context) {
+		called++
+		var m map[string]any
+		_ = ctx.BindJson(&m)
+	}
+	api := engine.Group("api")
+	api.Post("/small", handler)
+	api.Post("/big", handler).MaxBodyBytes(1 << 10)
+	body := `{"name":"0123456789abcdef"}`
+	post := func(path string, chunked bool) int {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if chunked {
+			r.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := post("/api/small", false); code != http.StatusRequestEntityTooLarge || called != 0 {
+		t.Fatalf("code %d, handler called %d times", code, called)
+	}
+	if code := post("/api/small", true); code != http.StatusRequestEntityTooLarge || called != 1 {
+		t.Fatalf("chunked: code %d, handler called %d times", code, called)
+	}
+	if code := post("/api/big", false); code != http.StatusOK {
+		t.Fatalf("route override: code %d", code)
+	}
+}
+
+func TestShouldBindBodyWith(t *testing.T) {
+	engine := New()
+	var raw []byte
+	engine.UseHandler(func(ctx *Context) {
+		//审计日志之类的中间件先读一次请求体
+		raw, _ = ctx.GetRawData()
+		ctx.Next()
+	})
+	type a struct {
+		Name string `json:"name"`
+	}
+	type b struct {
+		Age int `json:"age"`
+	}
+	var first a
+	var second b
+	var errs []error
+	engine.Group("api").Post("/body", func(ctx *Context) {
+		errs = append(errs, ctx.ShouldBindBodyWith(&first, binding.JSON), ctx.ShouldBindBodyWith(&second, binding.JSON))
+	})
+	body := `{"name":"ms","age":18}`
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/body", strings.NewReader(body)))
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if string(raw) != body || first.Name != "ms" || second.Age != 18 {
+		t.Fatalf("raw %q, first %+v, second %+v", raw, first, second)
+	}
+}
@@ -0,0 +1,54 @@
+package codec
+
+import (
+	"encoding/json"
+	"io"
+)
+
+//JSONCodec json的编解码 render binding rpc 都通过 codec.JSON 处理json
+//启动时可以换成 json-iterator sonic 等更快的实现 要在处理请求之前替换
+// codec.JSON = codec.JsonIterator()
+type JSONCodec interface {
+	Marshal(v any) ([]byte, error)
+	MarshalIndent(v any, prefix, indent string) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	NewEncoder(w io.Writer) JSONEncoder
+	NewDecoder(r io.Reader) JSONDecoder
+}
+
+type JSONEncoder interface {
+	Encode(v any) error
+	SetEscapeHTML(on bool)
+	SetIndent(prefix, indent string)
+}
+
+type JSONDecoder interface {
+	Decode(v any) error
+	DisallowUnknownFields()
+	UseNumber()
+}
+
+var JSON JSONCodec = StdJSON{}
+
+//StdJSON 标准库 encoding/json
+type StdJSON struct{}
+
+func (StdJSON) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (StdJSON) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(v, prefix, indent)
+}
+
+func (StdJSON) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (StdJSON) NewEncoder(w io.Writer) JSONEncoder {
+	return json.NewEncoder(w)
+}
+
+func (StdJSON) NewDecoder(r io.Reader) JSONDecoder {
+	return json.NewDecoder(r)
+}
@@ -0,0 +1,83 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+)
+
+type goods struct {
+	Id      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Price   float64  `json:"price"`
+	Tags    []string `json:"tags"`
+	OnSale  bool     `json:"onSale"`
+	Comment string   `json:"comment,omitempty"`
+}
+
+var (
+	benchGoods = goods{Id: 1000, Name: "<b>goods</b>", Price: 99.9, Tags: []string{"new", "hot"}, OnSale: true}
+	codecs     = map[string]JSONCodec{
+		"std":      StdJSON{},
+		"jsoniter": JsonIterator(),
+	}
+)
+
+//TestCodecCompatible 替换的实现输出要和标准库一致
+func TestCodecCompatible(t *testing.T) {
+	want, _ := StdJSON{}.Marshal(benchGoods)
+	for name, c := range codecs {
+		data, err := c.Marshal(benchGoods)
+		if err != nil || !bytes.Equal(data, want) {
+			t.Fatalf("%s: %s %v", name, data, err)
+		}
+		var g goods
+		decoder := c.NewDecoder(bytes.NewReader([]byte(`{"id":1,"unknown":1}`)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&g); err == nil {
+			t.Fatalf("%s: unknown field should fail", name)
+		}
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	for name, c := range codecs {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.Marshal(benchGoods); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	data, _ := StdJSON{}.Marshal(benchGoods)
+	for name, c := range codecs {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var g goods
+				if err := c.Unmarshal(data, &g); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	data, _ := StdJSON{}.Marshal(benchGoods)
+	for name, c := range codecs {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var g goods
+				if err := c.NewDecoder(bytes.NewReader(data)).Decode(&g); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package codec
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	"io"
+)
+
+//jsonIterator json-iterator 的实现 行为和标准库一致
+type jsonIterator struct {
+	api jsoniter.API
+}
+
+//JsonIterator 使用和标准库兼容的配置
+func JsonIterator() JSONCodec {
+	return jsonIterator{api: jsoniter.ConfigCompatibleWithStandardLibrary}
+}
+
+//JsonIteratorWithConfig 自定义配置 比如 jsoniter.ConfigFastest
+func JsonIteratorWithConfig(api jsoniter.API) JSONCodec {
+	return jsonIterator{api: api}
+}
+
+func (j jsonIterator) Marshal(v any) ([]byte, error) {
+	return j.api.Marshal(v)
+}
+
+func (j jsonIterator) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return j.api.MarshalIndent(v, prefix, indent)
+}
+
+func (j jsonIterator) Unmarshal(data []byte, v any) error {
+	return j.api.Unmarshal(data, v)
+}
+
+func (j jsonIterator) NewEncoder(w io.Writer) JSONEncoder {
+	return j.api.NewEncoder(w)
+}
+
+func (j jsonIterator) NewDecoder(r io.Reader) JSONDecoder {
+	return j.api.NewDecoder(r)
+}
@@ -0,0 +1,318 @@
+package msgo
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"github.com/andybalholm/brotli"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	EncodingBrotli  = "br"
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+//defaultExcludedContentTypes 本身已经压缩过的类型 再压缩没有效果
+var defaultExcludedContentTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-brotli",
+	"application/x-rar-compressed",
+	"application/x-7z-compressed",
+	"application/pdf",
+	"application/octet-stream",
+}
+
+type CompressConfig struct {
+	//支持的压缩方式 客户端q值相同时按这里的顺序选择 默认 br gzip deflate
+	Encodings []string
+	//gzip和deflate的压缩级别 默认 gzip.DefaultCompression
+	Level int
+	//响应体小于这个字节数不压缩 默认1024
+	MinLength int
+	//不压缩的路径前缀
+	ExcludedPaths []string
+	//不压缩的Content-Type前缀 默认是图片 视频 压缩包等
+	ExcludedContentTypes []string
+}
+
+//Compress 按照 Accept-Encoding 压缩响应 默认配置
+func Compress(next HandlerFunc) HandlerFunc {
+	return CompressWithConfig(CompressConfig{})(next)
+}
+
+//CompressWithConfig 压缩响应 先缓冲 MinLength 个字节再决定是否压缩
+//调用 Flush 时马上开始压缩 流式响应和SSE也可以使用
+func CompressWithConfig(conf CompressConfig) MiddlewareFunc {
+	if len(conf.Encodings) == 0 {
+		conf.Encodings = []string{EncodingBrotli, EncodingGzip, EncodingDeflate}
+	}
+	if conf.Level == 0 {
+		conf.Level = gzip.DefaultCompression
+	}
+	if conf.MinLength <= 0 {
+		conf.MinLength = 1024
+	}
+	if conf.ExcludedContentTypes == nil {
+		conf.ExcludedContentTypes = defaultExcludedContentTypes
+	}
+	pools := make(map[string]*sync.Pool, len(conf.Encodings))
+	for _, encoding := range conf.Encodings {
+		newCompressor, ok := compressors[encoding]
+		if !ok {
+			panic("msgo: unsupported compress encoding " + encoding)
+		}
+		level := conf.Level
+		pools[encoding] = &sync.Pool{New: func() any {
+			return newCompressor(level)
+		}}
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			if ctx.R.Method == http.MethodHead || conf.excludedPath(ctx.R.URL.Path) {
+				next(ctx)
+				return
+			}
+			addVary(ctx.W.Header(), "Accept-Encoding")
+			encoding := negotiateEncoding(ctx.GetHeader("Accept-Encoding"), conf.Encodings)
+			if encoding == "" {
+				next(ctx)
+				return
+			}
+			w := ctx.W
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				pool:           pools[encoding],
+				conf:           &conf,
+			}
+			ctx.W = cw
+			defer func() {
+				cw.close()
+				ctx.W = w
+			}()
+			next(ctx)
+		}
+	}
+}
+
+func (conf *CompressConfig) excludedPath(path string) bool {
+	for _, prefix := range conf.ExcludedPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (conf *CompressConfig) excludedContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range conf.ExcludedContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var compressors = map[string]func(level int) compressor{
+	EncodingBrotli: func(level int) compressor {
+		return brotli.NewWriterLevel(nil, brotli.DefaultCompression)
+	},
+	EncodingGzip: func(level int) compressor {
+		w, err := gzip.NewWriterLevel(nil, level)
+		if err != nil {
+			panic(err)
+		}
+		return w
+	},
+	EncodingDeflate: func(level int) compressor {
+		w, err := flate.NewWriter(nil, level)
+		if err != nil {
+			panic(err)
+		}
+		return w
+	},
+}
+
+//negotiateEncoding 选q值最大的 q值相同按配置的顺序 identity或者都不支持返回空
+func negotiateEncoding(acceptEncoding string, encodings []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	qs := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(params[0]))
+		if value == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			key, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.TrimSpace(key) == "q" {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = f
+				}
+			}
+		}
+		qs[value] = q
+	}
+	best, bestQ := "", 0.0
+	for _, encoding := range encodings {
+		q, ok := qs[encoding]
+		if !ok {
+			q = qs["*"]
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+func addVary(header http.Header, value string) {
+	for _, v := range header.Values("Vary") {
+		for _, field := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), value) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", value)
+}
+
+//compressWriter 响应先写到buf 够 MinLength 或者 Flush 时决定是否压缩
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	pool     *sync.Pool
+	conf     *CompressConfig
+	status   int
+	buf      []byte
+	decided  bool
+	writer   compressor
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.conf.MinLength {
+			return len(data), nil
+		}
+		n := len(data)
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+	if w.writer != nil {
+		return w.writer.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+//decide 发送响应头 把缓冲的数据写出去 large表示数据足够多或者需要流式输出
+func (w *compressWriter) decide(large bool) error {
+	w.decided = true
+	header := w.ResponseWriter.Header()
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		//压缩之后net/http就没法判断类型了
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	if large && w.compressible(header) {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", w.encoding)
+		w.writer = w.pool.Get().(compressor)
+		w.writer.Reset(w.ResponseWriter)
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.writer != nil {
+		_, err = w.writer.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (w *compressWriter) compressible(header http.Header) bool {
+	switch w.status {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	return !w.conf.excludedContentType(header.Get("Content-Type"))
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(true)
+	}
+	if w.writer != nil {
+		_ = w.writer.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.New("the ResponseWriter doesn't support the Hijacker interface")
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+//close 请求结束 数据不够 MinLength 的不压缩
+func (w *compressWriter) close() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.writer != nil {
+		_ = w.writer.Close()
+		w.writer.Reset(nil)
+		w.pool.Put(w.writer)
+		w.writer = nil
+	}
+}
@@ -0,0 +1,93 @@
+package msgo
+
+import (
+	"compress/gzip"
+	"github.com/andybalholm/brotli"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	engine := New()
+	engine.Use(CompressWithConfig(CompressConfig{ExcludedPaths: []string{"/raw/"}}))
+	large := strings.Repeat("msgo ", 400)
+	engine.Group("api").Get("/large", func(ctx *Context) {
+		ctx.W.Header().Set("Content-Length", "2000")
+		_ = ctx.String(http.StatusOK, large)
+	})
+	engine.Group("api").Get("/small", func(ctx *Context) {
+		_ = ctx.String(http.StatusOK, "ok")
+	})
+	engine.Group("api").Get("/image", func(ctx *Context) {
+		ctx.W.Header().Set("Content-Type", "image/png")
+		_, _ = ctx.W.Write([]byte(large))
+	})
+	engine.Group("raw").Get("/large", func(ctx *Context) {
+		_ = ctx.String(http.StatusOK, large)
+	})
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		//第二次用的是池子里的writer
+		w := get("/api/large", "gzip, deflate")
+		if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" || w.Header().Get("Content-Length") != "" {
+			t.Fatalf("headers %v", w.Header())
+		}
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body, _ := io.ReadAll(zr); string(body) != large {
+			t.Fatalf("gzip body %d bytes", len(body))
+		}
+	}
+
+	w := get("/api/large", "gzip;q=0.5, br")
+	if w.Header().Get("Content-Encoding") != "br" {
+		t.Fatalf("encoding %q", w.Header().Get("Content-Encoding"))
+	}
+	if body, _ := io.ReadAll(brotli.NewReader(w.Body)); string(body) != large {
+		t.Fatalf("br body %d bytes", len(body))
+	}
+	for _, path := range []string{"/api/small", "/api/image", "/raw/large"} {
+		if w := get(path, "gzip"); w.Header().Get("Content-Encoding") != "" {
+			t.Fatalf("%s should not be compressed", path)
+		}
+	}
+	if w := get("/api/large", "identity, gzip;q=0"); w.Header().Get("Content-Encoding") != "" {
+		t.Fatal("gzip;q=0 should not be compressed")
+	}
+}
+
+func TestCompressStream(t *testing.T) {
+	engine := New()
+	engine.Use(Compress)
+	engine.Group("order").Get("/status", func(ctx *Context) {
+		_ = ctx.SSEvent("status", "paid")
+		_ = ctx.SSEvent("status", "shipped")
+	})
+	r := httptest.NewRequest(http.MethodGet, "/order/status", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, r)
+	if w.Header().Get("Content-Encoding") != "gzip" || !w.Flushed {
+		t.Fatalf("headers %v, flushed %v", w.Header(), w.Flushed)
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(zr)
+	if string(body) != "event: status\ndata: paid\n\nevent: status\ndata: shipped\n\n" {
+		t.Fatalf("body %q", body)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/BurntSushi/toml"
 	msLog "github.com/mszlu521/msgo/log"
 	"os"
+	"strings"
 )
 
 var Conf = &MsConfig{
@@ -22,16 +23,40 @@ func init() {
 	loadToml()
 }
 
+//configFile 注册在全局的 flag 上 应用自己调用 flag.Parse 时能识别 -conf
+var configFile = flag.String("conf", "conf/app.toml", "app config file")
+
 func loadToml() {
-	configFile := flag.String("conf", "conf/app.toml", "app config file")
-	flag.Parse()
-	if _, err := os.Stat(*configFile); err != nil {
+	file := confArg(os.Args[1:], *configFile)
+	if _, err := os.Stat(file); err != nil {
 		Conf.logger.Info("conf/app.toml file not load，because not exist")
 		return
 	}
-	_, err := toml.DecodeFile(*configFile, Conf)
+	_, err := toml.DecodeFile(file, Conf)
 	if err != nil {
 		Conf.logger.Info("conf/app.toml decode fail check format")
 		return
 	}
 }
+
+//confArg 从命令行中取 -conf 的值
+//init 时不能调用 flag.Parse 应用和 go test 的参数这时候还没有注册 解析会直接退出
+func confArg(args []string, defaultValue string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		if len(arg)-len(name) == 0 || len(arg)-len(name) > 2 {
+			continue
+		}
+		if strings.HasPrefix(name, "conf=") {
+			return strings.TrimPrefix(name, "conf=")
+		}
+		if name == "conf" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return defaultValue
+}
@@ -1,23 +1,31 @@
 package msgo
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"github.com/mszlu521/msgo/binding"
 	msLog "github.com/mszlu521/msgo/log"
 	"github.com/mszlu521/msgo/render"
-	"html/template"
+	"google.golang.org/protobuf/proto"
 	"io"
 	"log"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 const defaultMultipartMemory = 32 << 20 //32M
 
+//abortIndex Abort之后index直接跳到这里，处理链中剩下的都不再执行
+const abortIndex = math.MaxInt16
+
 type Context struct {
 	W                     http.ResponseWriter
 	R                     *http.Request
@@ -31,6 +39,150 @@ type Context struct {
 	Keys                  map[string]any
 	mu                    sync.RWMutex
 	sameSite              http.SameSite
+	params                Params
+	handlers              HandlersChain
+	index                 int
+	writermem             responseWriter
+	//读取过的请求体 多个绑定器和中间件可以重复使用
+	rawData []byte
+}
+
+//reset Context是从池子里面复用的 每次请求前要清空上一次请求的数据
+func (c *Context) reset() {
+	c.queryCache = nil
+	c.formCache = nil
+	c.DisallowUnknownFields = false
+	c.IsValidate = false
+	c.StatusCode = 0
+	c.Keys = nil
+	c.sameSite = 0
+	c.params = c.params[:0]
+	c.handlers = c.handlers[:0]
+	c.index = -1
+	c.rawData = nil
+}
+
+var _ context.Context = &Context{}
+
+//Context 实现了 context.Context 可以直接传给下游的rpc grpc调用
+//客户端断开或者超时 下游能收到取消 Context是复用的，不要在请求结束之后的协程里使用
+
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	if c.R == nil {
+		return
+	}
+	return c.R.Context().Deadline()
+}
+
+func (c *Context) Done() <-chan struct{} {
+	if c.R == nil {
+		return nil
+	}
+	return c.R.Context().Done()
+}
+
+func (c *Context) Err() error {
+	if c.R == nil {
+		return nil
+	}
+	return c.R.Context().Err()
+}
+
+//Value string类型的key先从Set的值里面找 再从请求的context里面找
+func (c *Context) Value(key any) any {
+	if key == msgoContextKey {
+		return c
+	}
+	if keyAsString, ok := key.(string); ok {
+		if value, exists := c.Get(keyAsString); exists {
+			return value
+		}
+	}
+	if c.R == nil {
+		return nil
+	}
+	return c.R.Context().Value(key)
+}
+
+//Writer 记录了状态码和响应大小的 ResponseWriter
+//中间件替换了 W 之后 这里拿到的还是最外层的 记录的是真正发送出去的数据
+func (c *Context) Writer() ResponseWriter {
+	return &c.writermem
+}
+
+//Next 执行处理链中后面的处理函数，只能在中间件中调用
+func (c *Context) Next() {
+	c.index++
+	for c.index < len(c.handlers) {
+		c.handlers[c.index](c)
+		c.index++
+	}
+}
+
+//Abort 阻止后面的处理函数执行，当前的处理函数会继续执行完
+func (c *Context) Abort() {
+	c.index = abortIndex
+}
+
+func (c *Context) IsAborted() bool {
+	return c.index >= abortIndex
+}
+
+//AbortWithStatus 响应头通过 c.W 发送 中间件替换了 c.W (压缩 超时)时由它决定什么时候真正写出
+func (c *Context) AbortWithStatus(code int) {
+	c.StatusCode = code
+	c.W.WriteHeader(code)
+	if w, ok := c.W.(interface{ WriteHeaderNow() }); ok {
+		w.WriteHeaderNow()
+	}
+	c.Abort()
+}
+
+func (c *Context) AbortWithStatusJSON(code int, obj any) error {
+	c.Abort()
+	return c.JSON(code, obj)
+}
+
+//http://xxx.com/user/get/:id -> Param("id")
+
+func (c *Context) Param(name string) string {
+	return c.params.ByName(name)
+}
+
+func (c *Context) GetParam(name string) (string, bool) {
+	return c.params.Get(name)
+}
+
+func (c *Context) GetDefaultParam(name, defaultValue string) string {
+	value, ok := c.params.Get(name)
+	if !ok {
+		return defaultValue
+	}
+	return value
+}
+
+func (c *Context) Params() Params {
+	return c.params
+}
+
+func (c *Context) ParamInt(name string) (int, error) {
+	return strconv.Atoi(c.Param(name))
+}
+
+func (c *Context) ParamInt64(name string) (int64, error) {
+	return strconv.ParseInt(c.Param(name), 10, 64)
+}
+
+func (c *Context) ParamUint64(name string) (uint64, error) {
+	return strconv.ParseUint(c.Param(name), 10, 64)
+}
+
+func (c *Context) ParamFloat64(name string) (float64, error) {
+	return strconv.ParseFloat(c.Param(name), 64)
+}
+
+func (c *Context) ParamBool(name string) (bool, error) {
+	return strconv.ParseBool(c.Param(name))
 }
 
 func (c *Context) SetSameSite(s http.SameSite) {
@@ -119,7 +271,7 @@ func (c *Context) get(cache map[string][]string, key string) (map[string]string,
 
 func (c *Context) initPostFormCache() {
 	if c.R != nil {
-		if err := c.R.ParseMultipartForm(defaultMultipartMemory); err != nil {
+		if err := c.R.ParseMultipartForm(c.engine.MaxMultipartMemory); err != nil {
 			if !errors.Is(err, http.ErrNotMultipart) {
 				log.Println(err)
 			}
@@ -192,7 +344,7 @@ func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error
 }
 
 func (c *Context) MultipartForm() (*multipart.Form, error) {
-	err := c.R.ParseMultipartForm(defaultMultipartMemory)
+	err := c.R.ParseMultipartForm(c.engine.MaxMultipartMemory)
 	return c.R.MultipartForm, err
 }
 
@@ -201,40 +353,41 @@ func (c *Context) HTML(status int, html string) error {
 	return c.Render(status, &render.HTML{Data: html, IsTemplate: false})
 }
 
+//HTMLTemplate 解析好的模板会缓存 debug模式下文件修改后重新解析
 func (c *Context) HTMLTemplate(name string, data any, filenames ...string) error {
-	//状态是200 默认不设置的话 如果调用了 write这个方法 实际上默认返回状态 200
-	c.W.Header().Set("Content-Type", "text/html; charset=utf-8")
-	t := template.New(name)
-	t, err := t.ParseFiles(filenames...)
-	if err != nil {
-		return err
-	}
-	err = t.Execute(c.W, data)
-	return err
+	return c.htmlFiles(name, data, filenames...)
 }
 
 func (c *Context) HTMLTemplateGlob(name string, data any, pattern string) error {
-	//状态是200 默认不设置的话 如果调用了 write这个方法 实际上默认返回状态 200
-	c.W.Header().Set("Content-Type", "text/html; charset=utf-8")
-	t := template.New(name)
-	t, err := t.ParseGlob(pattern)
+	return c.htmlFiles(name, data, pattern)
+}
+
+func (c *Context) htmlFiles(name string, data any, patterns ...string) error {
+	t, err := c.engine.fileTemplates.Files(patterns...)
 	if err != nil {
 		return err
 	}
-	err = t.Execute(c.W, data)
-	return err
-}
-
-func (c *Context) Template(name string, data any) error {
 	//状态是200 默认不设置的话 如果调用了 write这个方法 实际上默认返回状态 200
 	return c.Render(http.StatusOK, &render.HTML{
 		Data:       data,
 		IsTemplate: true,
-		Template:   c.engine.HTMLRender.Template,
+		Template:   t,
 		Name:       name,
 	})
 }
 
+//Template 使用 engine.HTMLRender 渲染 name是模板名字或者模板集合的名字
+func (c *Context) Template(name string, data any) error {
+	return c.Render(http.StatusOK, c.htmlInstance(name, data))
+}
+
+func (c *Context) htmlInstance(name string, data any) render.Render {
+	if c.engine.HTMLRender == nil {
+		panic("html render is not set, load templates first")
+	}
+	return c.engine.HTMLRender.Instance(name, data)
+}
+
 func (c *Context) JSON(status int, data any) error {
 	//状态是200 默认不设置的话 如果调用了 write这个方法 实际上默认返回状态 200
 	return c.Render(status, &render.JSON{Data: data})
@@ -246,6 +399,73 @@ func (c *Context) XML(status int, data any) error {
 	})
 }
 
+//IndentedJSON 格式化的json 不建议在生产环境使用 会多占带宽
+func (c *Context) IndentedJSON(status int, data any) error {
+	return c.Render(status, &render.IndentedJSON{Data: data})
+}
+
+//PureJSON html字符 < > & 原样输出 不转义成 \u003c
+func (c *Context) PureJSON(status int, data any) error {
+	return c.Render(status, &render.PureJSON{Data: data})
+}
+
+//SecureJSON 返回数组时加上前缀 默认是 while(1); 可以用 engine.SecureJsonPrefix 修改
+func (c *Context) SecureJSON(status int, data any) error {
+	return c.Render(status, &render.SecureJSON{Prefix: c.engine.secureJSONPrefix, Data: data})
+}
+
+//JSONP 回调函数名取查询参数 callback 没有的话按json返回
+func (c *Context) JSONP(status int, data any) error {
+	return c.Render(status, &render.JSONP{Callback: c.GetQuery("callback"), Data: data})
+}
+
+func (c *Context) YAML(status int, data any) error {
+	return c.Render(status, &render.YAML{Data: data})
+}
+
+func (c *Context) TOML(status int, data any) error {
+	return c.Render(status, &render.TOML{Data: data})
+}
+
+func (c *Context) ProtoBuf(status int, data proto.Message) error {
+	return c.Render(status, &render.ProtoBuf{Data: data})
+}
+
+func (c *Context) MsgPack(status int, data any) error {
+	return c.Render(status, &render.MsgPack{Data: data})
+}
+
+//SSEvent 发送一条 Server-Sent Events 消息 写完立即flush
+func (c *Context) SSEvent(name string, data any) error {
+	err := c.Render(c.Writer().Status(), &render.SSE{Event: name, Data: data})
+	c.flush()
+	return err
+}
+
+//Stream 循环调用step 每次调用后flush step返回false或者客户端断开时结束
+//客户端断开时返回true
+func (c *Context) Stream(step func(w io.Writer) bool) bool {
+	clientGone := c.R.Context().Done()
+	for {
+		select {
+		case <-clientGone:
+			return true
+		default:
+			keepOpen := step(c.W)
+			c.flush()
+			if !keepOpen {
+				return false
+			}
+		}
+	}
+}
+
+func (c *Context) flush() {
+	if f, ok := c.W.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func (c *Context) File(fileName string) {
 	http.ServeFile(c.W, c.R, fileName)
 }
@@ -301,16 +521,162 @@ func (c *Context) BindXML(obj any) error {
 	return c.MustBindWith(obj, binding.XML)
 }
 
+//Bind 根据请求方式和Content-Type自动选择绑定器 失败返回400
+func (c *Context) Bind(obj any) error {
+	return c.MustBindWith(obj, c.defaultBinding())
+}
+
+func (c *Context) BindQuery(obj any) error {
+	return c.MustBindWith(obj, binding.Query)
+}
+
+func (c *Context) BindHeader(obj any) error {
+	return c.MustBindWith(obj, binding.Header)
+}
+
+func (c *Context) BindUri(obj any) error {
+	if err := c.ShouldBindUri(obj); err != nil {
+		c.W.WriteHeader(bindErrorStatus(err))
+		return err
+	}
+	return nil
+}
+
+//MustBindWith 绑定失败返回400 请求体超过限制返回413
 func (c *Context) MustBindWith(obj any, bind binding.Binding) error {
 	if err := c.ShouldBind(obj, bind); err != nil {
-		c.W.WriteHeader(http.StatusBadRequest)
+		c.W.WriteHeader(bindErrorStatus(err))
 		return err
 	}
 	return nil
 }
 
-func (c *Context) ShouldBind(obj any, bind binding.Binding) error {
-	return bind.Bind(c.R, obj)
+//ShouldBind 不传绑定器时根据Content-Type自动选择
+func (c *Context) ShouldBind(obj any, bind ...binding.Binding) error {
+	b := c.defaultBinding()
+	if len(bind) > 0 {
+		b = bind[0]
+	}
+	if b == binding.Form || b == binding.FormMultipart {
+		//按引擎的 MaxMultipartMemory 先解析 绑定器中不会再解析
+		if err := c.parseMultipartForm(); err != nil {
+			return err
+		}
+	}
+	return b.Bind(c.R, obj)
+}
+
+func (c *Context) parseMultipartForm() error {
+	if c.R.MultipartForm != nil || c.ContentType() != binding.MIMEMultipartPOSTForm {
+		return nil
+	}
+	return c.R.ParseMultipartForm(c.engine.MaxMultipartMemory)
+}
+
+//ShouldBindBodyWith 请求体读取一次后缓存在Context中 可以用不同的绑定器多次绑定
+func (c *Context) ShouldBindBodyWith(obj any, bb binding.BindingBody) error {
+	body, err := c.GetRawData()
+	if err != nil {
+		return err
+	}
+	return bb.BindBody(body, obj)
+}
+
+//GetRawData 返回请求体 第一次调用时读取并缓存
+//读取之后 ctx.R.Body 会换成缓存的内容 后面直接读 ctx.R.Body 的代码不受影响
+func (c *Context) GetRawData() ([]byte, error) {
+	if c.rawData != nil {
+		return c.rawData, nil
+	}
+	if c.R.Body == nil {
+		return nil, errors.New("invalid request")
+	}
+	body, err := io.ReadAll(c.R.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.rawData = body
+	c.R.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func (c *Context) ShouldBindQuery(obj any) error {
+	return c.ShouldBind(obj, binding.Query)
+}
+
+func (c *Context) ShouldBindHeader(obj any) error {
+	return c.ShouldBind(obj, binding.Header)
+}
+
+//ShouldBindUri 绑定路由中的参数 uri:"id"
+func (c *Context) ShouldBindUri(obj any) error {
+	m := make(map[string][]string, len(c.params))
+	for _, p := range c.params {
+		m[p.Key] = []string{p.Value}
+	}
+	return binding.Uri.BindUri(m, obj)
+}
+
+//ValidationErrors 把绑定失败的错误转换为当前请求语言的 FieldErrors
+func (c *Context) ValidationErrors(err error) (binding.FieldErrors, bool) {
+	return binding.ToFieldErrors(err, c.acceptLocales()...)
+}
+
+//BindError 绑定失败返回400 校验错误返回 {"errors":[{field,tag,param,message}]}
+func (c *Context) BindError(err error) error {
+	if isBodyTooLarge(err) {
+		return c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, map[string]any{"error": err.Error()})
+	}
+	if fieldErrs, ok := c.ValidationErrors(err); ok {
+		return c.AbortWithStatusJSON(http.StatusBadRequest, map[string]any{"errors": fieldErrs})
+	}
+	return c.AbortWithStatusJSON(http.StatusBadRequest, map[string]any{"error": err.Error()})
+}
+
+func bindErrorStatus(err error) int {
+	if isBodyTooLarge(err) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+//isBodyTooLarge http.MaxBytesReader 读取超过限制返回的错误 解析表单时会被包装
+func isBodyTooLarge(err error) bool {
+	return strings.Contains(err.Error(), "http: request body too large")
+}
+
+//acceptLocales Accept-Language: zh-CN,en;q=0.8 -> zh_cn zh en
+func (c *Context) acceptLocales() []string {
+	specs := parseAccept(c.GetHeader("Accept-Language"))
+	locales := make([]string, 0, len(specs)*2)
+	for _, spec := range specs {
+		if spec.q <= 0 {
+			continue
+		}
+		locale := strings.ReplaceAll(spec.value, "-", "_")
+		locales = append(locales, locale)
+		if lang, _, ok := strings.Cut(locale, "_"); ok {
+			locales = append(locales, lang)
+		}
+	}
+	return locales
+}
+
+//ContentType 去掉参数部分 如 application/json; charset=utf-8
+func (c *Context) ContentType() string {
+	ct, _, _ := strings.Cut(c.R.Header.Get("Content-Type"), ";")
+	return strings.TrimSpace(ct)
+}
+
+func (c *Context) defaultBinding() binding.Binding {
+	b := binding.Default(c.R.Method, c.ContentType())
+	if b == binding.JSON {
+		json := binding.JSON
+		json.DisallowUnknownFields = c.DisallowUnknownFields
+		json.IsValidate = c.IsValidate
+		return json
+	}
+	return b
 }
 
 func (c *Context) Fail(code int, msg string) {
@@ -0,0 +1,129 @@
+package msgo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestContextParam(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Get("/get/:id", func(ctx *Context) {
+		id, err := ctx.ParamInt("id")
+		if err != nil {
+			ctx.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		ctx.String(http.StatusOK, "%d", id)
+	})
+	g.Get("/file/**path", func(ctx *Context) {
+		ctx.String(http.StatusOK, ctx.Param("path"))
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user/get/7", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "7" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user/get/abc", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user/file/a/b.txt", nil))
+	if w.Body.String() != "a/b.txt" {
+		t.Fatalf("got %q", w.Body.String())
+	}
+}
+
+func TestContextNextAbort(t *testing.T) {
+	engine := New()
+	var trace []string
+	engine.UseHandler(func(ctx *Context) {
+		trace = append(trace, "global before")
+		ctx.Next()
+		trace = append(trace, "global after")
+	})
+	engine.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			trace = append(trace, "middleware")
+			next(ctx)
+		}
+	})
+	g := engine.Group("user")
+	g.UseHandler(func(ctx *Context) {
+		if ctx.GetQuery("token") == "" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, map[string]string{"msg": "no token"})
+			return
+		}
+	})
+	g.Get("/info", func(ctx *Context) {
+		trace = append(trace, "handler")
+		ctx.String(http.StatusOK, "info")
+	})
+
+	w := performRequest(engine, http.MethodGet, "/user/info?token=1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d", w.Code)
+	}
+	want := []string{"global before", "middleware", "handler", "global after"}
+	if fmt.Sprint(trace) != fmt.Sprint(want) {
+		t.Fatalf("want %v, got %v", want, trace)
+	}
+
+	trace = trace[:0]
+	w = performRequest(engine, http.MethodGet, "/user/info")
+	if w.Code != http.StatusUnauthorized || w.Body.String() != `{"msg":"no token"}` {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	for _, v := range trace {
+		if v == "handler" {
+			t.Fatalf("handler should not run after abort, got %v", trace)
+		}
+	}
+}
+
+func TestMiddlewareFuncReturnEarly(t *testing.T) {
+	engine := New()
+	engine.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			ctx.AbortWithStatus(http.StatusForbidden)
+		}
+	})
+	g := engine.Group("user")
+	called := false
+	g.Get("/info", func(ctx *Context) {
+		called = true
+	})
+	w := performRequest(engine, http.MethodGet, "/user/info")
+	if w.Code != http.StatusForbidden || called {
+		t.Fatalf("got %d, handler called: %v", w.Code, called)
+	}
+}
+
+func TestAbortWithStatusWrappedWriter(t *testing.T) {
+	engine := New()
+	gz := engine.Group("gzip")
+	gz.Use(Compress)
+	gz.Get("/auth", func(ctx *Context) {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+	})
+	timeout := engine.Group("timeout")
+	timeout.Use(Timeout(time.Second))
+	timeout.Get("/auth", func(ctx *Context) {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+	})
+	for _, path := range []string{"/gzip/auth", "/timeout/auth"} {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("%s got %d", path, w.Code)
+		}
+	}
+}
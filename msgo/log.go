@@ -42,6 +42,7 @@ type LogFormatterParams struct {
 	Request        *http.Request
 	TimeStamp      time.Time
 	StatusCode     int
+	BodySize       int
 	Latency        time.Duration
 	ClientIP       net.IP
 	Method         string
@@ -70,18 +71,20 @@ var defaultFormatter = func(params *LogFormatterParams) string {
 		params.Latency = params.Latency.Truncate(time.Second)
 	}
 	if params.IsDisplayColor {
-		return fmt.Sprintf("%s [msgo] %s |%s %v %s| %s %3d %s |%s %13v %s| %15s  |%s %-7s %s %s %#v %s \n",
+		return fmt.Sprintf("%s [msgo] %s |%s %v %s| %s %3d %s | %8d |%s %13v %s| %15s  |%s %-7s %s %s %#v %s \n",
 			yellow, resetColor, blue, params.TimeStamp.Format("2006/01/02 - 15:04:05"), resetColor,
 			statusCodeColor, params.StatusCode, resetColor,
+			params.BodySize,
 			red, params.Latency, resetColor,
 			params.ClientIP,
 			magenta, params.Method, resetColor,
 			cyan, params.Path, resetColor,
 		)
 	}
-	return fmt.Sprintf("[msgo] %v | %3d | %13v | %15s |%-7s %#v",
+	return fmt.Sprintf("[msgo] %v | %3d | %8d | %13v | %15s |%-7s %#v",
 		params.TimeStamp.Format("2006/01/02 - 15:04:05"),
 		params.StatusCode,
+		params.BodySize,
 		params.Latency, params.ClientIP, params.Method, params.Path,
 	)
 
@@ -114,7 +117,7 @@ func LoggingWithConfig(conf LoggingConfig, next HandlerFunc) HandlerFunc {
 		ip, _, _ := net.SplitHostPort(strings.TrimSpace(ctx.R.RemoteAddr))
 		clientIP := net.ParseIP(ip)
 		method := r.Method
-		statusCode := ctx.StatusCode
+		statusCode := ctx.Writer().Status()
 
 		if raw != "" {
 			path = path + "?" + raw
@@ -122,6 +125,10 @@ func LoggingWithConfig(conf LoggingConfig, next HandlerFunc) HandlerFunc {
 
 		param.TimeStamp = stop
 		param.StatusCode = statusCode
+		param.BodySize = ctx.Writer().Size()
+		if param.BodySize < 0 {
+			param.BodySize = 0
+		}
 		param.Latency = latency
 		param.Path = path
 		param.ClientIP = clientIP
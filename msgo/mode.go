@@ -0,0 +1,39 @@
+package msgo
+
+import (
+	"os"
+)
+
+const EnvMsgoMode = "MSGO_MODE"
+
+const (
+	DebugMode   = "debug"
+	ReleaseMode = "release"
+	TestMode    = "test"
+)
+
+var msgoMode = DebugMode
+
+func init() {
+	SetMode(os.Getenv(EnvMsgoMode))
+}
+
+//SetMode 设置运行模式 debug模式下启动时会打印路由表
+func SetMode(value string) {
+	switch value {
+	case DebugMode, "":
+		msgoMode = DebugMode
+	case ReleaseMode, TestMode:
+		msgoMode = value
+	default:
+		panic("msgo mode unknown: " + value + " (available mode: debug release test)")
+	}
+}
+
+func Mode() string {
+	return msgoMode
+}
+
+func IsDebugging() bool {
+	return msgoMode == DebugMode
+}
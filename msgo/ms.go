@@ -8,93 +8,171 @@ import (
 	"github.com/mszlu521/msgo/register"
 	"github.com/mszlu521/msgo/render"
 	"html/template"
+	"io/fs"
 	"log"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 const ANY = "ANY"
 
 type HandlerFunc func(ctx *Context)
 
+//HandlersChain 一个请求要执行的处理链 中间件在前 业务处理函数在最后
+type HandlersChain []HandlerFunc
+
 type MiddlewareFunc func(handlerFunc HandlerFunc) HandlerFunc
 
+//ToHandler 把 MiddlewareFunc 适配成处理链中的一环
+//中间件没有调用next就返回，认为请求被拦截，后续的处理不再执行
+func (m MiddlewareFunc) ToHandler() HandlerFunc {
+	h := m(func(ctx *Context) {
+		ctx.Next()
+	})
+	return func(ctx *Context) {
+		index := ctx.index
+		h(ctx)
+		if ctx.index == index {
+			ctx.Abort()
+		}
+	}
+}
+
+func toHandlers(middlewareFuncs []MiddlewareFunc) HandlersChain {
+	handlers := make(HandlersChain, len(middlewareFuncs))
+	for i, middlewareFunc := range middlewareFuncs {
+		handlers[i] = middlewareFunc.ToHandler()
+	}
+	return handlers
+}
+
 type routerGroup struct {
 	name               string
+	prefix             string
+	parent             *routerGroup
 	handleFuncMap      map[string]map[string]HandlerFunc
-	middlewaresFuncMap map[string]map[string][]MiddlewareFunc
+	middlewaresFuncMap map[string]map[string]HandlersChain
+	bodyLimitMap       map[string]map[string]int64
 	handlerMethodMap   map[string][]string
-	treeNode           *treeNode
-	middlewares        []MiddlewareFunc
+	middlewares        HandlersChain
+	engine             *Engine
 }
 
 func (r *routerGroup) Use(middlewareFunc ...MiddlewareFunc) {
-	r.middlewares = append(r.middlewares, middlewareFunc...)
+	r.middlewares = append(r.middlewares, toHandlers(middlewareFunc)...)
 }
 
-func (r *routerGroup) methodHandle(name string, method string, h HandlerFunc, ctx *Context) {
-	//组通用中间件
-	if r.middlewares != nil {
-		for _, middlewareFunc := range r.middlewares {
-			h = middlewareFunc(h)
-		}
+//UseHandler 添加 ctx.Next() 风格的中间件
+func (r *routerGroup) UseHandler(handlers ...HandlerFunc) {
+	r.middlewares = append(r.middlewares, handlers...)
+}
+
+//Group 创建子组，子组继承父组的前缀和中间件
+// api := engine.Group("api")
+// v1 := api.Group("/v1")  -> /api/v1
+func (r *routerGroup) Group(name string) *routerGroup {
+	group := r.engine.router.newGroup(name, joinPaths(r.prefix, name))
+	group.parent = r
+	return group
+}
+
+//combineHandlers 组装处理链 父组中间件 -> 组中间件 -> 路由中间件 -> 处理函数
+//请求时再取 父组后加的中间件也能生效
+func (r *routerGroup) combineHandlers(handlers HandlersChain, name string, method string) HandlersChain {
+	handlers = r.appendMiddlewares(handlers)
+	handlers = append(handlers, r.middlewaresFuncMap[name][method]...)
+	return append(handlers, r.handleFuncMap[name][method])
+}
+
+//bodyLimit 路由单独设置的请求体大小优先 否则用引擎的 MaxBodyBytes
+func (r *routerGroup) bodyLimit(name string, method string) int64 {
+	if limit, ok := r.bodyLimitMap[name][method]; ok {
+		return limit
 	}
-	//组路由级别
-	middlewareFuncs := r.middlewaresFuncMap[name][method]
-	if middlewareFuncs != nil {
-		for _, middlewareFunc := range middlewareFuncs {
-			h = middlewareFunc(h)
-		}
+	return r.engine.MaxBodyBytes
+}
+
+func (r *routerGroup) appendMiddlewares(handlers HandlersChain) HandlersChain {
+	if r.parent != nil {
+		handlers = r.parent.appendMiddlewares(handlers)
 	}
-	h(ctx)
+	return append(handlers, r.middlewares...)
 }
 
 //func (r *routerGroup) Add(Name string, handleFunc HandlerFunc) {
 //	r.handleFuncMap[Name] = handleFunc
 //}
 
-func (r *routerGroup) handle(name string, method string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	_, ok := r.handleFuncMap[name]
+func (r *routerGroup) handle(name string, method string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.addRoute(name, method, append(toHandlers(middlewareFunc), handlerFunc))
+}
+
+//Handle 注册路由 最后一个是处理函数 前面的是 ctx.Next() 风格的路由级别中间件
+func (r *routerGroup) Handle(method string, name string, handlers ...HandlerFunc) *Route {
+	if len(handlers) == 0 {
+		panic("there must be at least one handler")
+	}
+	return r.addRoute(name, method, handlers)
+}
+
+func (r *routerGroup) addRoute(name string, method string, handlers HandlersChain) *Route {
+	//路由统一用完整路径 比如 /api/v1/user/get/:id
+	name = joinPaths(r.prefix, name)
+	router := &r.engine.router
+	_, ok := router.groupMap[name]
 	if !ok {
-		r.handleFuncMap[name] = make(map[string]HandlerFunc)
-		r.middlewaresFuncMap[name] = make(map[string][]MiddlewareFunc)
+		router.groupMap[name] = make(map[string]*routerGroup)
 	}
-	_, ok = r.handleFuncMap[name][method]
+	_, ok = router.groupMap[name][method]
 	if ok {
-		panic("有重复的路由")
+		panic(fmt.Sprintf("有重复的路由: %s %s", method, name))
+	}
+	_, ok = r.handleFuncMap[name]
+	if !ok {
+		r.handleFuncMap[name] = make(map[string]HandlerFunc)
+		r.middlewaresFuncMap[name] = make(map[string]HandlersChain)
 	}
-	r.handleFuncMap[name][method] = handlerFunc
-	r.middlewaresFuncMap[name][method] = append(r.middlewaresFuncMap[name][method], middlewareFunc...)
-	r.treeNode.Put(name)
+	last := len(handlers) - 1
+	router.groupMap[name][method] = r
+	r.handleFuncMap[name][method] = handlers[last]
+	r.middlewaresFuncMap[name][method] = handlers[:last:last]
+	router.treeNode.Put(name).addMethod(method)
+	if n := countParams(name); n > r.engine.maxParams {
+		r.engine.maxParams = n
+	}
+	return &Route{engine: r.engine, Method: method, Path: name}
 }
 
-func (r *routerGroup) Any(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, ANY, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Any(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, ANY, handlerFunc, middlewareFunc...)
 }
 
-func (r *routerGroup) Get(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodGet, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Get(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodGet, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Post(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodPost, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Post(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodPost, handlerFunc, middlewareFunc...)
 }
 
-func (r *routerGroup) Delete(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodDelete, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Delete(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodDelete, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Put(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodPut, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Put(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodPut, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Patch(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodPatch, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Patch(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodPatch, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Options(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodOptions, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Options(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodOptions, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Head(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodHead, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Head(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodHead, handlerFunc, middlewareFunc...)
 }
 
 //user  get->handle
@@ -103,17 +181,51 @@ func (r *routerGroup) Head(name string, handlerFunc HandlerFunc, middlewareFunc
 type router struct {
 	routerGroups []*routerGroup
 	engine       *Engine
+	//所有组的路由都放在一棵树上 路径 -> 请求方式 -> 注册的组
+	//树的节点上记录了注册的请求方式 查找时跳过不能处理当前请求方式的路由
+	treeNode *treeNode
+	groupMap map[string]map[string]*routerGroup
+	//路由名字 -> 路由 用来反向生成URL
+	namedRoutes map[string]*Route
+}
+
+//allowed 匹配上的路由注册的请求方式 比如 GET, HEAD, OPTIONS, POST
+//GET会自动支持HEAD，所有路由都自动支持OPTIONS
+func (r *router) allowed(nodes []*treeNode) string {
+	set := map[string]bool{http.MethodOptions: true}
+	for _, node := range nodes {
+		for method := range r.groupMap[node.routerName] {
+			if method == ANY {
+				continue
+			}
+			set[method] = true
+			if method == http.MethodGet {
+				set[http.MethodHead] = true
+			}
+		}
+	}
+	methods := make([]string, 0, len(set))
+	for method := range set {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
 }
 
 func (r *router) Group(name string) *routerGroup {
+	return r.newGroup(name, joinPaths("/", name))
+}
+
+func (r *router) newGroup(name string, prefix string) *routerGroup {
 	routerGroup := &routerGroup{
 		name:               name,
+		prefix:             prefix,
 		handleFuncMap:      make(map[string]map[string]HandlerFunc),
-		middlewaresFuncMap: make(map[string]map[string][]MiddlewareFunc),
+		middlewaresFuncMap: make(map[string]map[string]HandlersChain),
+		bodyLimitMap:       make(map[string]map[string]int64),
 		handlerMethodMap:   make(map[string][]string),
-		treeNode:           &treeNode{name: "/", children: make([]*treeNode, 0)},
+		engine:             r.engine,
 	}
-	routerGroup.Use(r.engine.middles...)
 	r.routerGroups = append(r.routerGroups, routerGroup)
 	return routerGroup
 }
@@ -124,9 +236,10 @@ type Engine struct {
 	router
 	funcMap          template.FuncMap
 	HTMLRender       render.HTMLRender
+	fileTemplates    *render.HTMLTemplates
 	pool             sync.Pool
 	Logger           *msLog.Logger
-	middles          []MiddlewareFunc
+	middles          HandlersChain
 	errorHandler     ErrorHandler
 	OpenGateway      bool
 	gatewayConfigs   []gateway.GWConfig
@@ -135,14 +248,39 @@ type Engine struct {
 	RegisterType     string
 	RegisterOption   register.Option
 	RegisterCli      register.MsRegister
+	maxParams        int
+	noRoute          HandlersChain
+	noMethod         HandlersChain
+	//优雅关闭时等待请求处理完的最长时间
+	ShutdownTimeout time.Duration
+	//请求体的最大字节数 超过返回413 0表示不限制
+	MaxBodyBytes int64
+	//解析multipart表单时放在内存中的最大字节数 超过的部分存到临时文件
+	MaxMultipartMemory int64
+	secureJSONPrefix   string
+	startHooks         []func() error
+	shutdownHooks      []func() error
+	server             *Server
+	serverLock         sync.Mutex
 }
 
 func New() *Engine {
 	engine := &Engine{
-		router:           router{},
-		gatewayTreeNode:  &gateway.TreeNode{Name: "/", Children: make([]*gateway.TreeNode, 0)},
-		gatewayConfigMap: make(map[string]gateway.GWConfig),
+		router: router{
+			treeNode:    &treeNode{name: "/", children: make([]*treeNode, 0)},
+			groupMap:    make(map[string]map[string]*routerGroup),
+			namedRoutes: make(map[string]*Route),
+		},
+		gatewayTreeNode:    &gateway.TreeNode{Name: "/", Children: make([]*gateway.TreeNode, 0)},
+		gatewayConfigMap:   make(map[string]gateway.GWConfig),
+		MaxMultipartMemory: defaultMultipartMemory,
+		secureJSONPrefix:   "while(1);",
 	}
+	engine.router.engine = engine
+	engine.fileTemplates = render.NewHTMLTemplates(engine.templateFuncMap())
+	engine.fileTemplates.Reload = IsDebugging()
+	engine.NoRoute()
+	engine.NoMethod()
 	engine.pool.New = func() any {
 		return engine.allocateContext()
 	}
@@ -157,12 +295,11 @@ func Default() *Engine {
 		engine.Logger.SetLogPath(logPath.(string))
 	}
 	engine.Use(Logging, Recovery)
-	engine.router.engine = engine
 	return engine
 }
 
 func (e *Engine) allocateContext() any {
-	return &Context{engine: e}
+	return &Context{engine: e, params: make(Params, 0, e.maxParams)}
 }
 
 func (e *Engine) SetGatewayConfig(configs []gateway.GWConfig) {
@@ -174,33 +311,89 @@ func (e *Engine) SetGatewayConfig(configs []gateway.GWConfig) {
 	}
 }
 
+//SecureJsonPrefix 设置 ctx.SecureJSON 使用的前缀
+func (e *Engine) SecureJsonPrefix(prefix string) *Engine {
+	e.secureJSONPrefix = prefix
+	return e
+}
+
 func (e *Engine) SetFuncMap(funcMap template.FuncMap) {
 	e.funcMap = funcMap
+	e.fileTemplates.FuncMap = e.templateFuncMap()
 }
 
+//LoadTemplate 加载模板 ctx.Template 按模板名字渲染
+//debug模式下模板文件修改后会重新加载 其它模式只解析一次
 func (e *Engine) LoadTemplate(pattern string) {
-	t := template.Must(template.New("").Funcs(e.funcMap).ParseGlob(pattern))
-	e.SetHtmlTemplate(t)
+	if err := e.templates().AddFromFiles("", pattern); err != nil {
+		panic(err)
+	}
+}
+
+//LoadTemplateFS 从 embed.FS 等文件系统中加载模板
+func (e *Engine) LoadTemplateFS(fsys fs.FS, patterns ...string) {
+	if err := e.templates().AddFromFS("", fsys, patterns...); err != nil {
+		panic(err)
+	}
+}
+
+//LoadTemplateSet 加载命名的模板集合 第一个文件是布局 ctx.Template(name, data) 渲染这个布局
+// e.LoadTemplateSet("user", "templates/base.html", "templates/user.html")
+func (e *Engine) LoadTemplateSet(name string, patterns ...string) {
+	if err := e.templates().AddFromFiles(name, patterns...); err != nil {
+		panic(err)
+	}
+}
+
+func (e *Engine) LoadTemplateSetFS(name string, fsys fs.FS, patterns ...string) {
+	if err := e.templates().AddFromFS(name, fsys, patterns...); err != nil {
+		panic(err)
+	}
 }
 
 func (e *Engine) LoadTemplateConf() {
 	pattern, ok := config.Conf.Template["pattern"]
 	if ok {
-		t := template.Must(template.New("").Funcs(e.funcMap).ParseGlob(pattern.(string)))
-		e.SetHtmlTemplate(t)
+		e.LoadTemplate(pattern.(string))
+	}
+}
+
+//templates 引擎默认的模板集合 自定义了 HTMLRender 的会被替换掉
+func (e *Engine) templates() *render.HTMLTemplates {
+	if t, ok := e.HTMLRender.(*render.HTMLTemplates); ok {
+		return t
 	}
+	t := render.NewHTMLTemplates(e.templateFuncMap())
+	t.Reload = IsDebugging()
+	e.HTMLRender = t
+	return t
+}
+
+//templateFuncMap 模板中默认可以用 {{url "user.get" "id" 7}} 生成路由地址
+func (e *Engine) templateFuncMap() template.FuncMap {
+	funcMap := template.FuncMap{
+		"url": e.URL,
+	}
+	for name, f := range e.funcMap {
+		funcMap[name] = f
+	}
+	return funcMap
 }
 
 func (e *Engine) SetHtmlTemplate(t *template.Template) {
-	e.HTMLRender = render.HTMLRender{Template: t}
+	e.HTMLRender = render.HTMLProduction{Template: t}
 }
 
 func (e *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := e.pool.Get().(*Context)
-	ctx.W = w
+	ctx.writermem.reset(w)
+	ctx.W = &ctx.writermem
 	ctx.R = r
 	ctx.Logger = e.Logger
-	e.httpRequestHandle(ctx, w, r)
+	ctx.reset()
+	e.httpRequestHandle(ctx, ctx.W, r)
+	//只调用了WriteHeader没有写响应体的 在这里发送响应头
+	ctx.writermem.WriteHeaderNow()
 
 	e.pool.Put(ctx)
 }
@@ -251,32 +444,111 @@ func (e *Engine) httpRequestHandle(ctx *Context, w http.ResponseWriter, r *http.
 		proxy.ServeHTTP(w, r)
 		return
 	}
+	e.routeHandle(ctx)
+}
+
+//routeHandle 全局中间件在请求时才组装，和Use、Group的调用顺序无关，404 405也会经过
+//处理链放在池化的Context上复用，不用每次请求都分配
+func (e *Engine) routeHandle(ctx *Context) {
+	r := ctx.R
 	method := r.Method
-	for _, group := range e.routerGroups {
-		routerName := SubStringLast(r.URL.Path, "/"+group.name)
-		// get/1
-		node := group.treeNode.Get(routerName)
-		if node != nil && node.isEnd {
-			//路由匹配上了
-			handle, ok := group.handleFuncMap[node.routerName][ANY]
-			if ok {
-				group.methodHandle(node.routerName, ANY, handle, ctx)
-				return
-			}
-			handle, ok = group.handleFuncMap[node.routerName][method]
-			if ok {
-				group.methodHandle(node.routerName, method, handle, ctx)
-				return
-			}
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			fmt.Fprintf(w, "%s %s not allowed \n", r.RequestURI, method)
-			return
+	handlers := append(ctx.handlers[:0], e.middles...)
+	// /user/get/1
+	node := e.treeNode.GetMethod(r.URL.Path, method, &ctx.params)
+	if node == nil {
+		//所有请求方式都匹配不上才是405
+		ctx.params = ctx.params[:0]
+		nodes := e.treeNode.matchAll(r.URL.Path, nil)
+		if len(nodes) == 0 {
+			handlers = append(handlers, e.noRoute...)
+		} else if method == http.MethodOptions {
+			ctx.W.Header().Set("Allow", e.allowed(nodes))
+			handlers = append(handlers, optionsHandle)
+		} else {
+			ctx.W.Header().Set("Allow", e.allowed(nodes))
+			handlers = append(handlers, e.noMethod...)
 		}
+		ctx.handlers = handlers
+		ctx.Next()
+		return
+	}
+	routes := e.groupMap[node.routerName]
+	if group, ok := routes[ANY]; ok {
+		//路由匹配上了
+		handlers = e.routeHandlers(ctx, handlers, group, node.routerName, ANY)
+	} else if group, ok = routes[method]; ok {
+		handlers = e.routeHandlers(ctx, handlers, group, node.routerName, method)
+	} else {
+		//HEAD 用 GET 的处理，响应体由net/http丢弃
+		handlers = e.routeHandlers(ctx, handlers, routes[http.MethodGet], node.routerName, http.MethodGet)
+	}
+	ctx.handlers = handlers
+	ctx.Next()
+}
+
+//routeHandlers 组装路由的处理链 请求体超过限制时不执行路由的中间件和处理函数 直接返回413
+func (e *Engine) routeHandlers(ctx *Context, handlers HandlersChain, group *routerGroup, name string, method string) HandlersChain {
+	r := ctx.R
+	if limit := group.bodyLimit(name, method); limit > 0 && r.Body != nil && r.Body != http.NoBody {
+		if r.ContentLength > limit {
+			return append(handlers, entityTooLargeHandle)
+		}
+		//没有Content-Length或者和实际不符时 读取超过限制会报错
+		r.Body = http.MaxBytesReader(ctx.writermem.ResponseWriter, r.Body, limit)
+	}
+	return group.combineHandlers(handlers, name, method)
+}
+
+func notFoundHandle(ctx *Context) {
+	ctx.String(http.StatusNotFound, "%s  not found \n", ctx.R.RequestURI)
+}
+
+func methodNotAllowedHandle(ctx *Context) {
+	ctx.String(http.StatusMethodNotAllowed, "%s %s not allowed \n", ctx.R.RequestURI, ctx.R.Method)
+}
+
+func entityTooLargeHandle(ctx *Context) {
+	ctx.String(http.StatusRequestEntityTooLarge, "%s request body too large \n", ctx.R.RequestURI)
+}
+
+func optionsHandle(ctx *Context) {
+	ctx.StatusCode = http.StatusNoContent
+	ctx.W.WriteHeader(http.StatusNoContent)
+}
+
+//serveNoRoute 在处理函数中转到404的处理 比如静态文件不存在
+//404的处理函数panic时也要换回原来的处理链 否则Context放回池子后会往 e.noRoute 里追加
+func (e *Engine) serveNoRoute(ctx *Context) {
+	handlers := ctx.handlers
+	defer func() {
+		ctx.handlers = handlers
+		ctx.Abort()
+	}()
+	ctx.handlers = e.noRoute
+	ctx.index = -1
+	ctx.Next()
+}
+
+//NoRoute 自定义404的处理 会经过全局中间件
+func (e *Engine) NoRoute(handlers ...HandlerFunc) {
+	e.noRoute = handlers
+	if len(e.noRoute) == 0 {
+		e.noRoute = HandlersChain{notFoundHandle}
 	}
-	w.WriteHeader(http.StatusNotFound)
-	fmt.Fprintf(w, "%s  not found \n", r.RequestURI)
 }
 
+//NoMethod 自定义405的处理 响应头中的Allow已经设置好了
+func (e *Engine) NoMethod(handlers ...HandlerFunc) {
+	e.noMethod = handlers
+	if len(e.noMethod) == 0 {
+		e.noMethod = HandlersChain{methodNotAllowedHandle}
+	}
+}
+
+//Run 启动服务并阻塞，收到 SIGINT/SIGTERM 后优雅关闭 启动失败直接 log.Fatal
+//Run 不返回句柄 需要自己调用 Shutdown(ctx) 或者处理启动错误的用 Start
+// server, err := engine.Start(":8080")
+// defer server.Shutdown(ctx)
 func (e *Engine) Run(addr string) {
 	//user  key:get value: func
 	//for _, group := range e.routerGroups {
@@ -284,11 +556,28 @@ func (e *Engine) Run(addr string) {
 	//		http.HandlerFunc("/"+group.Name+key, value)
 	//	}
 	//}
+	server, err := e.Start(addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	e.waitShutdown(server)
+}
+
+//RunTLS 和 Run 一样阻塞到收到信号 需要句柄的用 StartTLS
+func (e *Engine) RunTLS(addr, certFile, keyFile string) {
+	server, err := e.StartTLS(addr, certFile, keyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	e.waitShutdown(server)
+}
+
+func (e *Engine) createRegisterCli() error {
 	if e.RegisterType == "nacos" {
 		r := &register.MsNacosRegister{}
 		err := r.CreateCli(e.RegisterOption)
 		if err != nil {
-			panic(err)
+			return err
 		}
 		e.RegisterCli = r
 	}
@@ -296,26 +585,30 @@ func (e *Engine) Run(addr string) {
 		r := &register.MsEtcdRegister{}
 		err := r.CreateCli(e.RegisterOption)
 		if err != nil {
-			panic(err)
+			return err
 		}
 		e.RegisterCli = r
 	}
-	http.Handle("/", e)
-	err := http.ListenAndServe(addr, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
+	return nil
 }
 
-func (e *Engine) RunTLS(addr, certFile, keyFile string) {
-	err := http.ListenAndServeTLS(addr, certFile, keyFile, e.Handler())
-	if err != nil {
-		log.Fatal(err)
-	}
+//OnStart 监听成功之后、开始处理请求之前执行，返回错误则服务不启动
+func (e *Engine) OnStart(hook func() error) {
+	e.startHooks = append(e.startHooks, hook)
+}
+
+//OnShutdown 请求处理完之后按注册顺序执行 比如 e.OnShutdown(closer.Close) 刷新tracer
+func (e *Engine) OnShutdown(hook func() error) {
+	e.shutdownHooks = append(e.shutdownHooks, hook)
 }
 
 func (e *Engine) Use(middles ...MiddlewareFunc) {
-	e.middles = append(e.middles, middles...)
+	e.middles = append(e.middles, toHandlers(middles)...)
+}
+
+//UseHandler 添加 ctx.Next() 风格的全局中间件
+func (e *Engine) UseHandler(handlers ...HandlerFunc) {
+	e.middles = append(e.middles, handlers...)
 }
 
 func (e *Engine) RegisterErrorHandler(handler ErrorHandler) {
@@ -0,0 +1,288 @@
+package msgo
+
+import (
+	"context"
+	"fmt"
+	"github.com/mszlu521/msgo/register"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func performRequest(e *Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestNestedGroup(t *testing.T) {
+	engine := New()
+	var trace []string
+	mark := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx *Context) {
+				trace = append(trace, name)
+				next(ctx)
+			}
+		}
+	}
+	api := engine.Group("api")
+	v1 := api.Group("/v1")
+	v1.Use(mark("v1"))
+	v1.Get("/user/get/:id", func(ctx *Context) {
+		ctx.String(http.StatusOK, "v1 "+ctx.Param("id"))
+	})
+	//子组创建之后父组再加中间件 也要生效
+	api.Use(mark("api"))
+	user := engine.Group("user")
+	user.Get("/info", func(ctx *Context) {
+		ctx.String(http.StatusOK, "user")
+	})
+
+	w := performRequest(engine, http.MethodGet, "/api/v1/user/get/7")
+	if w.Code != http.StatusOK || w.Body.String() != "v1 7" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	if len(trace) != 2 || trace[0] != "api" || trace[1] != "v1" {
+		t.Fatalf("parent middleware should run first, got %v", trace)
+	}
+	//组名只按前缀匹配 /api/user/info 不能匹配到 user 组
+	w = performRequest(engine, http.MethodGet, "/api/user/info")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("got %d", w.Code)
+	}
+	w = performRequest(engine, http.MethodGet, "/user/info")
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d", w.Code)
+	}
+}
+
+func TestEngineMiddlewareOrder(t *testing.T) {
+	engine := New()
+	count := 0
+	g := engine.Group("user")
+	g.Get("/info", func(ctx *Context) {
+		ctx.String(http.StatusOK, "info")
+	})
+	//Group之后再Use 也要对组生效
+	engine.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			count++
+			next(ctx)
+		}
+	})
+
+	performRequest(engine, http.MethodGet, "/user/info")
+	w := performRequest(engine, http.MethodGet, "/user/none")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("got %d", w.Code)
+	}
+	w = performRequest(engine, http.MethodPost, "/user/info")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("got %d", w.Code)
+	}
+	if count != 3 {
+		t.Fatalf("global middleware should run for 200, 404 and 405, got %d", count)
+	}
+}
+
+func TestNoRouteNoMethod(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Get("/info", func(ctx *Context) {
+		ctx.String(http.StatusOK, "info")
+	})
+	g.Post("/info", func(ctx *Context) {
+		ctx.String(http.StatusOK, "post info")
+	})
+	engine.NoRoute(func(ctx *Context) {
+		ctx.JSON(http.StatusNotFound, map[string]string{"msg": "not found"})
+	})
+	engine.NoMethod(func(ctx *Context) {
+		ctx.JSON(http.StatusMethodNotAllowed, map[string]string{"msg": "not allowed"})
+	})
+
+	w := performRequest(engine, http.MethodGet, "/user/none")
+	if w.Code != http.StatusNotFound || w.Body.String() != `{"msg":"not found"}` {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	w = performRequest(engine, http.MethodDelete, "/user/info")
+	if w.Code != http.StatusMethodNotAllowed || w.Body.String() != `{"msg":"not allowed"}` {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS, POST" {
+		t.Fatalf("got Allow %q", allow)
+	}
+	w = performRequest(engine, http.MethodOptions, "/user/info")
+	if w.Code != http.StatusNoContent || w.Header().Get("Allow") != "GET, HEAD, OPTIONS, POST" {
+		t.Fatalf("got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+	w = performRequest(engine, http.MethodHead, "/user/info")
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d", w.Code)
+	}
+}
+
+func TestRouteMethodBacktrack(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Get("/:id", func(ctx *Context) {
+		ctx.String(http.StatusOK, "get "+ctx.Param("id"))
+	})
+	g.Post("/info", func(ctx *Context) {
+		ctx.String(http.StatusOK, "post info")
+	})
+
+	//静态路由没有注册GET 回溯到参数路由
+	w := performRequest(engine, http.MethodGet, "/user/info")
+	if w.Code != http.StatusOK || w.Body.String() != "get info" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	w = performRequest(engine, http.MethodPost, "/user/info")
+	if w.Code != http.StatusOK || w.Body.String() != "post info" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	w = performRequest(engine, http.MethodDelete, "/user/info")
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, HEAD, OPTIONS, POST" {
+		t.Fatalf("got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+}
+
+func TestEngineStartShutdown(t *testing.T) {
+	engine := New()
+	var hooks []string
+	engine.OnStart(func() error {
+		hooks = append(hooks, "start")
+		return nil
+	})
+	engine.OnShutdown(func() error {
+		hooks = append(hooks, "shutdown")
+		return nil
+	})
+	g := engine.Group("user")
+	g.Get("/info", func(ctx *Context) {
+		ctx.String(http.StatusOK, "info")
+	})
+	server, err := engine.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get("http://" + server.Addr().String() + "/user/info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got %d", resp.StatusCode)
+	}
+	if err := engine.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	<-server.Done()
+	if err := server.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 2 || hooks[0] != "start" || hooks[1] != "shutdown" {
+		t.Fatalf("got hooks %v", hooks)
+	}
+}
+
+type fakeRegister struct {
+	services map[string]string
+}
+
+func (f *fakeRegister) CreateCli(option register.Option) error {
+	return nil
+}
+
+func (f *fakeRegister) RegisterService(serviceName string, host string, port int) error {
+	f.services[serviceName] = fmt.Sprintf("%s:%d", host, port)
+	return nil
+}
+
+func (f *fakeRegister) DeregisterService(serviceName string, host string, port int) error {
+	delete(f.services, serviceName)
+	return nil
+}
+
+func (f *fakeRegister) GetValue(serviceName string) (string, error) {
+	return f.services[serviceName], nil
+}
+
+func (f *fakeRegister) Close() error {
+	return nil
+}
+
+func TestEngineSelfRegister(t *testing.T) {
+	engine := New()
+	cli := &fakeRegister{services: make(map[string]string)}
+	engine.RegisterCli = cli
+	engine.RegisterOption = register.Option{ServiceName: "goodsCenter"}
+	server, err := engine.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr, _ := cli.GetValue("goodsCenter"); addr != server.Addr().String() {
+		t.Fatalf("want %s registered, got %q", server.Addr(), addr)
+	}
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cli.services["goodsCenter"]; ok {
+		t.Fatal("service should be deregistered after shutdown")
+	}
+}
+
+func TestEngineRoutes(t *testing.T) {
+	engine := New()
+	engine.Use(Logging)
+	api := engine.Group("api")
+	api.Use(Logging)
+	v1 := api.Group("/v1")
+	v1.Get("/user/get/:id", func(ctx *Context) {}, Logging)
+	v1.Post("/user", func(ctx *Context) {})
+
+	routes := engine.Routes()
+	if len(routes) != 2 {
+		t.Fatalf("got %v", routes)
+	}
+	if routes[0].Method != http.MethodPost || routes[0].Path != "/api/v1/user" || routes[0].MiddlewareCount != 2 {
+		t.Fatalf("got %+v", routes[0])
+	}
+	if routes[1].Method != http.MethodGet || routes[1].Path != "/api/v1/user/get/:id" || routes[1].MiddlewareCount != 3 {
+		t.Fatalf("got %+v", routes[1])
+	}
+	if !strings.HasPrefix(routes[1].Handler, "github.com/mszlu521/msgo.TestEngineRoutes") {
+		t.Fatalf("got handler %s", routes[1].Handler)
+	}
+}
+
+func TestEngineURL(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Get("/get/:id", func(ctx *Context) {}).Name("user.get")
+	g.Get("/file/**path", func(ctx *Context) {}).Name("user.file")
+
+	tests := []struct {
+		name  string
+		pairs []any
+		want  string
+	}{
+		{"user.get", []any{"id", 7}, "/user/get/7"},
+		{"user.get", []any{"id", "a b", "page", 2}, "/user/get/a%20b?page=2"},
+		{"user.file", []any{"path", "css/app.css"}, "/user/file/css/app.css"},
+	}
+	for _, tt := range tests {
+		got, err := engine.URL(tt.name, tt.pairs...)
+		if err != nil || got != tt.want {
+			t.Fatalf("%s %v: want %s, got %s %v", tt.name, tt.pairs, tt.want, got, err)
+		}
+	}
+	if _, err := engine.URL("user.get"); err == nil {
+		t.Fatal("missing param should fail")
+	}
+	if _, err := engine.URL("user.none", "id", 1); err == nil {
+		t.Fatal("unknown route should fail")
+	}
+}
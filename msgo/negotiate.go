@@ -0,0 +1,171 @@
+package msgo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mszlu521/msgo/binding"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrNotAcceptable = errors.New("the accepted formats are not offered by the server")
+
+//Negotiation 同一个接口根据 Accept 返回不同的格式
+//JSON XML 没有设置时用 Data HTML不会用 Data 避免把数据原样输出成页面
+type Negotiation struct {
+	//提供的格式 为空时按 JSON XML HTML 中设置了的 设置了 HTML 或者 HTMLName 才提供HTML
+	Offered  []string
+	Data     any
+	JSON     any
+	XML      any
+	HTML     any    //template.HTML 原样输出 其它类型转义后输出
+	HTMLName string //设置了就用模板渲染 HTML 作为模板数据
+}
+
+func (n Negotiation) offered() []string {
+	if len(n.Offered) > 0 {
+		return n.Offered
+	}
+	offered := make([]string, 0, 3)
+	if n.JSON != nil || n.Data != nil {
+		offered = append(offered, binding.MIMEJSON)
+	}
+	if n.XML != nil || n.Data != nil {
+		offered = append(offered, binding.MIMEXML)
+	}
+	if n.HTML != nil || n.HTMLName != "" {
+		offered = append(offered, binding.MIMEHTML)
+	}
+	return offered
+}
+
+func (n Negotiation) data(value any) any {
+	if value != nil {
+		return value
+	}
+	return n.Data
+}
+
+//Negotiate 按 Accept 选择格式渲染 没有匹配的格式响应406
+func (c *Context) Negotiate(code int, config Negotiation) error {
+	switch c.NegotiateFormat(config.offered()...) {
+	case binding.MIMEJSON:
+		return c.JSON(code, config.data(config.JSON))
+	case binding.MIMEXML, binding.MIMEXML2:
+		return c.XML(code, config.data(config.XML))
+	case binding.MIMEHTML:
+		if config.HTMLName != "" {
+			return c.Render(code, c.htmlInstance(config.HTMLName, config.HTML))
+		}
+		switch html := config.HTML.(type) {
+		case nil:
+			//Offered 中写了 text/html 但是没有提供HTML
+		case template.HTML:
+			return c.HTML(code, string(html))
+		default:
+			return c.HTML(code, template.HTMLEscapeString(fmt.Sprint(html)))
+		}
+		c.AbortWithStatus(http.StatusNotAcceptable)
+		return ErrNotAcceptable
+	case binding.MIMEPlain:
+		return c.String(code, fmt.Sprint(config.Data))
+	default:
+		c.AbortWithStatus(http.StatusNotAcceptable)
+		return ErrNotAcceptable
+	}
+}
+
+//NegotiateFormat 按 Accept 中的q值 从提供的格式中选出最合适的一个 没有匹配的返回空
+//没有 Accept 头的返回第一个 q=0 表示不接受这个格式
+func (c *Context) NegotiateFormat(offered ...string) string {
+	if len(offered) == 0 {
+		panic("you must provide at least one offer")
+	}
+	accept := c.R.Header.Get("Accept")
+	if accept == "" {
+		return offered[0]
+	}
+	specs := parseAccept(accept)
+	best, bestQ, bestIndex := "", 0.0, 0
+	for _, offer := range offered {
+		//q值相同时选 Accept 中排在前面的
+		q, index := quality(specs, offer)
+		if q > bestQ || (q > 0 && q == bestQ && index < bestIndex) {
+			best, bestQ, bestIndex = offer, q, index
+		}
+	}
+	return best
+}
+
+//quality offer的q值由匹配上的最具体的一项决定 比如 application/json;q=0, */* 中json的q值是0
+func quality(specs []acceptSpec, offer string) (float64, int) {
+	index := -1
+	for i, spec := range specs {
+		if spec.match(offer) && (index < 0 || spec.specificity() > specs[index].specificity()) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return 0, -1
+	}
+	return specs[index].q, index
+}
+
+type acceptSpec struct {
+	value string
+	q     float64
+}
+
+//specificity text/html > text/* > */*
+func (a acceptSpec) specificity() int {
+	switch {
+	case a.value == "*/*" || a.value == "*":
+		return 0
+	case strings.HasSuffix(a.value, "/*"):
+		return 1
+	}
+	return 2
+}
+
+func (a acceptSpec) match(offer string) bool {
+	switch a.specificity() {
+	case 0:
+		return true
+	case 1:
+		return strings.HasPrefix(offer, a.value[:len(a.value)-1])
+	}
+	return strings.EqualFold(a.value, offer)
+}
+
+//parseAccept text/html;q=0.9, application/json -> 按q值和具体程度排序 q=0的保留 用来排除格式
+func parseAccept(accept string) []acceptSpec {
+	specs := make([]acceptSpec, 0, 4)
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		value := strings.TrimSpace(params[0])
+		if value == "" {
+			continue
+		}
+		spec := acceptSpec{value: strings.ToLower(value), q: 1}
+		for _, param := range params[1:] {
+			key, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.TrimSpace(key) == "q" {
+				q, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+				if err == nil {
+					spec.q = q
+				}
+			}
+		}
+		specs = append(specs, spec)
+	}
+	sort.SliceStable(specs, func(i, j int) bool {
+		if specs[i].q != specs[j].q {
+			return specs[i].q > specs[j].q
+		}
+		return specs[i].specificity() > specs[j].specificity()
+	})
+	return specs
+}
@@ -0,0 +1,79 @@
+package msgo
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type negotiateUser struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func TestNegotiate(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Get("/info", func(ctx *Context) {
+		ctx.Negotiate(http.StatusOK, Negotiation{
+			Data: &negotiateUser{Name: "mszlu"},
+			HTML: template.HTML("<b>mszlu</b>"),
+		})
+	})
+
+	tests := []struct {
+		accept      string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"", http.StatusOK, "application/json; charset=utf-8", `{"name":"mszlu"}`},
+		{"application/xml", http.StatusOK, "application/xml; charset=utf-8", "<negotiateUser><name>mszlu</name></negotiateUser>"},
+		{"text/html;q=0.8, application/xml;q=0.9", http.StatusOK, "application/xml; charset=utf-8", "<negotiateUser><name>mszlu</name></negotiateUser>"},
+		{"text/*, application/json;q=0.5", http.StatusOK, "text/html; charset=utf-8", "<b>mszlu</b>"},
+		{"image/png", http.StatusNotAcceptable, "", ""},
+		//q=0 排除了json 不能再通过 */* 匹配
+		{"application/json;q=0, */*", http.StatusOK, "application/xml; charset=utf-8", "<negotiateUser><name>mszlu</name></negotiateUser>"},
+		{"*/*;q=0", http.StatusNotAcceptable, "", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/user/info", nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		if w.Code != tt.code || w.Header().Get("Content-Type") != tt.contentType || w.Body.String() != tt.body {
+			t.Fatalf("%q: got %d %q %q", tt.accept, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+}
+
+func TestNegotiateDataNotHTML(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Get("/data", func(ctx *Context) {
+		ctx.Negotiate(http.StatusOK, Negotiation{Data: &negotiateUser{Name: ctx.GetQuery("name")}})
+	})
+	g.Get("/escape", func(ctx *Context) {
+		ctx.Negotiate(http.StatusOK, Negotiation{HTML: ctx.GetQuery("name")})
+	})
+	browser := "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+	get := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Accept", browser)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+
+	w := get("/user/data?name=%3Cscript%3Ealert(1)%3C/script%3E")
+	if ct := w.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" || strings.Contains(w.Body.String(), "<script>") {
+		t.Fatalf("content type %q, body %q", ct, w.Body.String())
+	}
+	w = get("/user/escape?name=%3Cscript%3Ealert(1)%3C/script%3E")
+	if w.Body.String() != "&lt;script&gt;alert(1)&lt;/script&gt;" {
+		t.Fatalf("body %q", w.Body.String())
+	}
+}
@@ -25,6 +25,8 @@ func Recovery(next HandlerFunc) HandlerFunc {
 	return func(ctx *Context) {
 		defer func() {
 			if err := recover(); err != nil {
+				//panic之后处理链中剩下的不再执行
+				ctx.Abort()
 				err2 := err.(error)
 				if err2 != nil {
 					var msError *mserror.MsError
@@ -34,6 +36,10 @@ func Recovery(next HandlerFunc) HandlerFunc {
 					}
 				}
 				ctx.Logger.Error(detailMsg(err))
+				if ctx.writermem.Written() {
+					//响应已经发出去了 比如超时的503 只记录日志
+					return
+				}
 				ctx.Fail(http.StatusInternalServerError, "Internal Server Error")
 			}
 		}()
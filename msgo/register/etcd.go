@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"log"
+	"sync"
 	"time"
 )
 
@@ -37,8 +39,24 @@ func GetEtcdValue(cli *clientv3.Client, serviceName string) (string, error) {
 	return string(kvs[0].Value), err
 }
 
+const (
+	defaultTTL = 10 * time.Second
+	//租约失效后重新注册的间隔 每次失败翻倍
+	minRetryInterval = time.Second
+	maxRetryInterval = 30 * time.Second
+)
+
 type MsEtcdRegister struct {
-	cli *clientv3.Client
+	cli    *clientv3.Client
+	ttl    time.Duration
+	lock   sync.Mutex
+	leases map[string]*etcdLease
+}
+
+//etcdLease id 为0表示租约已经失效 正在重新注册
+type etcdLease struct {
+	id     clientv3.LeaseID
+	cancel context.CancelFunc
 }
 
 func (r *MsEtcdRegister) CreateCli(option Option) error {
@@ -47,13 +65,123 @@ func (r *MsEtcdRegister) CreateCli(option Option) error {
 		DialTimeout: option.DialTimeout, //超过5秒钟连不上超时
 	})
 	r.cli = cli
+	r.ttl = option.TTL
+	if r.ttl < time.Second {
+		r.ttl = defaultTTL
+	}
+	r.leases = make(map[string]*etcdLease)
 	return err
 }
 
+//RegisterService 带租约注册 后台一直续约，进程挂掉之后超过TTL自动下线
+//租约失效(过期 被撤销 和etcd断开太久)后会重新注册 直到调用 DeregisterService
 func (r *MsEtcdRegister) RegisterService(serviceName string, host string, port int) error {
+	value := fmt.Sprintf("%s:%d", host, port)
+	keepCtx, keepCancel := context.WithCancel(context.Background())
+	id, ch, err := r.grant(keepCtx, serviceName, value)
+	if err != nil {
+		keepCancel()
+		return err
+	}
+	lease := &etcdLease{id: id, cancel: keepCancel}
+	r.lock.Lock()
+	if old, ok := r.leases[serviceName]; ok {
+		old.cancel()
+	}
+	r.leases[serviceName] = lease
+	r.lock.Unlock()
+	go r.keepAlive(keepCtx, lease, serviceName, value, ch)
+	return nil
+}
+
+//grant 申请租约 带租约写入key 然后开始续约
+func (r *MsEtcdRegister) grant(keepCtx context.Context, key string, value string) (clientv3.LeaseID, <-chan *clientv3.LeaseKeepAliveResponse, error) {
+	ctx, cancel := context.WithTimeout(keepCtx, time.Second)
+	defer cancel()
+	lease, err := r.cli.Grant(ctx, int64(r.ttl/time.Second))
+	if err != nil {
+		return 0, nil, err
+	}
+	_, err = r.cli.Put(ctx, key, value, clientv3.WithLease(lease.ID))
+	if err != nil {
+		return 0, nil, err
+	}
+	ch, err := r.cli.KeepAlive(keepCtx, lease.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+	return lease.ID, ch, nil
+}
+
+//keepAlive 续约的响应要读掉 否则channel满了会打日志
+//channel关闭说明租约失效了 按退避间隔重新注册 ctx取消(注销)后退出
+func (r *MsEtcdRegister) keepAlive(ctx context.Context, lease *etcdLease, key string, value string, ch <-chan *clientv3.LeaseKeepAliveResponse) {
+	for {
+		for range ch {
+		}
+		r.lock.Lock()
+		lease.id = 0
+		r.lock.Unlock()
+		interval := minRetryInterval
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+			id, newCh, err := r.grant(ctx, key, value)
+			if err == nil {
+				r.lock.Lock()
+				stopped := ctx.Err() != nil
+				if !stopped {
+					lease.id = id
+				}
+				r.lock.Unlock()
+				if stopped {
+					//注册成功的同时被注销了 新的租约由这里撤销
+					_ = r.revoke(id)
+					return
+				}
+				ch = newCh
+				break
+			}
+			log.Printf("etcd register %s failed, retry after %s: %v", key, interval, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+			interval *= 2
+			if interval > maxRetryInterval {
+				interval = maxRetryInterval
+			}
+		}
+	}
+}
+
+func (r *MsEtcdRegister) revoke(id clientv3.LeaseID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := r.cli.Revoke(ctx, id)
+	return err
+}
+
+//DeregisterService 停止续约并撤销租约 key随租约一起删除
+func (r *MsEtcdRegister) DeregisterService(serviceName string, host string, port int) error {
+	r.lock.Lock()
+	lease, ok := r.leases[serviceName]
+	delete(r.leases, serviceName)
+	var id clientv3.LeaseID
+	if ok {
+		lease.cancel()
+		id = lease.id
+	}
+	r.lock.Unlock()
+	if id != 0 {
+		return r.revoke(id)
+	}
+	//没有租约或者租约已经失效 直接删除key
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	_, err := r.cli.Put(ctx, serviceName, fmt.Sprintf("%s:%d", host, port))
+	_, err := r.cli.Delete(ctx, serviceName)
 	return err
 }
 
@@ -9,6 +9,7 @@ type Option struct {
 	Endpoints         []string      //节点
 	DialTimeout       time.Duration //超时时间
 	ServiceName       string
+	TTL               time.Duration //注册的租约时间 服务挂掉之后超过这个时间自动下线 默认10秒
 	Host              string
 	Port              int
 	NacosServerConfig []constant.ServerConfig
@@ -18,6 +19,7 @@ type Option struct {
 type MsRegister interface {
 	CreateCli(option Option) error
 	RegisterService(serviceName string, host string, port int) error
+	DeregisterService(serviceName string, host string, port int) error
 	GetValue(serviceName string) (string, error)
 	Close() error
 }
@@ -12,10 +12,26 @@ type HTML struct {
 	Template   *template.Template
 	IsTemplate bool
 }
-type HTMLRender struct {
+
+//HTMLRender 根据模板名字得到对应的Render ctx.Template 使用
+type HTMLRender interface {
+	Instance(name string, data any) Render
+}
+
+//HTMLProduction 一个解析好的模板 按名字执行其中定义的模板
+type HTMLProduction struct {
 	Template *template.Template
 }
 
+func (h HTMLProduction) Instance(name string, data any) Render {
+	return &HTML{
+		Data:       data,
+		Name:       name,
+		Template:   h.Template,
+		IsTemplate: true,
+	}
+}
+
 func (h *HTML) Render(w http.ResponseWriter, code int) error {
 	h.WriteContentType(w)
 	w.WriteHeader(code)
@@ -1,8 +1,11 @@
 package render
 
 import (
-	"encoding/json"
+	"bytes"
+	"github.com/mszlu521/msgo/codec"
+	"github.com/mszlu521/msgo/internal/bytesconv"
 	"net/http"
+	"strings"
 )
 
 type JSON struct {
@@ -12,7 +15,7 @@ type JSON struct {
 func (j *JSON) Render(w http.ResponseWriter, code int) error {
 	j.WriteContentType(w)
 	w.WriteHeader(code)
-	jsonData, err := json.Marshal(j.Data)
+	jsonData, err := codec.JSON.Marshal(j.Data)
 	if err != nil {
 		return err
 	}
@@ -23,3 +26,112 @@ func (j *JSON) Render(w http.ResponseWriter, code int) error {
 func (j *JSON) WriteContentType(w http.ResponseWriter) {
 	writeContentType(w, "application/json; charset=utf-8")
 }
+
+//IndentedJSON 格式化输出 方便调试时查看
+type IndentedJSON struct {
+	Data any
+}
+
+func (j *IndentedJSON) Render(w http.ResponseWriter, code int) error {
+	j.WriteContentType(w)
+	w.WriteHeader(code)
+	jsonData, err := codec.JSON.MarshalIndent(j.Data, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(jsonData)
+	return err
+}
+
+func (j *IndentedJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/json; charset=utf-8")
+}
+
+//PureJSON 不转义 < > & 这些html字符
+type PureJSON struct {
+	Data any
+}
+
+func (j *PureJSON) Render(w http.ResponseWriter, code int) error {
+	j.WriteContentType(w)
+	w.WriteHeader(code)
+	encoder := codec.JSON.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(j.Data)
+}
+
+func (j *PureJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/json; charset=utf-8")
+}
+
+//SecureJSON 返回的是数组时加上前缀 防止json劫持
+type SecureJSON struct {
+	Prefix string
+	Data   any
+}
+
+func (j *SecureJSON) Render(w http.ResponseWriter, code int) error {
+	j.WriteContentType(w)
+	w.WriteHeader(code)
+	jsonData, err := codec.JSON.Marshal(j.Data)
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(jsonData, []byte("[")) && bytes.HasSuffix(jsonData, []byte("]")) {
+		if _, err = w.Write(bytesconv.StringToBytes(j.Prefix)); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(jsonData)
+	return err
+}
+
+func (j *SecureJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/json; charset=utf-8")
+}
+
+//JSONP callback中只保留js标识符允许的字符 没有callback时按json返回
+type JSONP struct {
+	Callback string
+	Data     any
+}
+
+func (j *JSONP) Render(w http.ResponseWriter, code int) error {
+	callback := sanitizeCallback(j.Callback)
+	if callback == "" {
+		return (&JSON{Data: j.Data}).Render(w, code)
+	}
+	j.WriteContentType(w)
+	w.WriteHeader(code)
+	jsonData, err := codec.JSON.Marshal(j.Data)
+	if err != nil {
+		return err
+	}
+	//开头的注释可以防止 Rosetta Flash 这类攻击
+	var b bytes.Buffer
+	b.WriteString("/**/ typeof ")
+	b.WriteString(callback)
+	b.WriteString(" === 'function' && ")
+	b.WriteString(callback)
+	b.WriteString("(")
+	b.Write(jsonData)
+	b.WriteString(");")
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+func (j *JSONP) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/javascript; charset=utf-8")
+}
+
+func sanitizeCallback(callback string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '_' || r == '$' || r == '.':
+			return r
+		}
+		return -1
+	}, callback)
+}
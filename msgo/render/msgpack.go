@@ -0,0 +1,20 @@
+package render
+
+import (
+	"github.com/vmihailenco/msgpack/v5"
+	"net/http"
+)
+
+type MsgPack struct {
+	Data any
+}
+
+func (m *MsgPack) Render(w http.ResponseWriter, code int) error {
+	m.WriteContentType(w)
+	w.WriteHeader(code)
+	return msgpack.NewEncoder(w).Encode(m.Data)
+}
+
+func (m *MsgPack) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/msgpack")
+}
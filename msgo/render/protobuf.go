@@ -0,0 +1,25 @@
+package render
+
+import (
+	"google.golang.org/protobuf/proto"
+	"net/http"
+)
+
+type ProtoBuf struct {
+	Data proto.Message
+}
+
+func (p *ProtoBuf) Render(w http.ResponseWriter, code int) error {
+	p.WriteContentType(w)
+	w.WriteHeader(code)
+	bytes, err := proto.Marshal(p.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bytes)
+	return err
+}
+
+func (p *ProtoBuf) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/x-protobuf")
+}
@@ -0,0 +1,83 @@
+package render
+
+import (
+	"fmt"
+	"github.com/mszlu521/msgo/codec"
+	"io"
+	"net/http"
+	"strings"
+)
+
+//SSE Server-Sent Events 的一条消息
+//Data 是字符串或者[]byte时原样输出 其它类型转成json 多行数据每行一个 data:
+type SSE struct {
+	Event string
+	ID    string
+	Retry uint
+	Data  any
+}
+
+var fieldReplacer = strings.NewReplacer("\n", "\\n", "\r", "\\r")
+
+//lineReplacer \r\n \r \n 都是换行 统一成 \n 再按行输出 否则单独的 \r 会被当成新的一行
+var lineReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+func (s *SSE) Render(w http.ResponseWriter, code int) error {
+	s.WriteContentType(w)
+	w.WriteHeader(code)
+	return s.Encode(w)
+}
+
+func (s *SSE) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	writeContentType(w, "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	//nginx默认会缓冲响应
+	header.Set("X-Accel-Buffering", "no")
+}
+
+//Encode 按照 event stream 的格式写出 以空行结束
+func (s *SSE) Encode(w io.Writer) error {
+	var b strings.Builder
+	if s.ID != "" {
+		b.WriteString("id: ")
+		b.WriteString(fieldReplacer.Replace(s.ID))
+		b.WriteString("\n")
+	}
+	if s.Event != "" {
+		b.WriteString("event: ")
+		b.WriteString(fieldReplacer.Replace(s.Event))
+		b.WriteString("\n")
+	}
+	if s.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", s.Retry)
+	}
+	data, err := sseData(s.Data)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(lineReplacer.Replace(data), "\n") {
+		b.WriteString("data: ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func sseData(data any) (string, error) {
+	switch d := data.(type) {
+	case string:
+		return d, nil
+	case []byte:
+		return string(d), nil
+	case nil:
+		return "", nil
+	}
+	jsonData, err := codec.JSON.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
@@ -0,0 +1,192 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+//HTMLTemplates 多个命名的模板集合 每个集合由布局和页面组成
+// t.AddFromFiles("user", "templates/base.html", "templates/user/*.html")
+//base.html 中用 {{template "content" .}} 引用页面中定义的 content
+//渲染集合时执行的是第一个文件 名字为空的集合可以按模板名字渲染其中的任意模板
+type HTMLTemplates struct {
+	FuncMap template.FuncMap
+	//开发模式 渲染前检查文件 有修改或者增删时重新解析
+	Reload bool
+	mu     sync.RWMutex
+	sets   map[string]*templateSet
+}
+
+type templateSet struct {
+	fsys     fs.FS
+	patterns []string
+	mu       sync.Mutex
+	tmpl     *template.Template
+	files    []string
+	modTime  time.Time
+}
+
+func NewHTMLTemplates(funcMap template.FuncMap) *HTMLTemplates {
+	return &HTMLTemplates{FuncMap: funcMap, sets: make(map[string]*templateSet)}
+}
+
+//AddFromFiles 从磁盘加载模板集合 patterns 可以是文件名或者通配符
+func (t *HTMLTemplates) AddFromFiles(name string, patterns ...string) error {
+	return t.AddFromFS(name, nil, patterns...)
+}
+
+//AddFromFS 从 fs.FS 加载模板集合 比如 embed.FS
+func (t *HTMLTemplates) AddFromFS(name string, fsys fs.FS, patterns ...string) error {
+	if len(patterns) == 0 {
+		return errors.New("html template: no files")
+	}
+	set := &templateSet{fsys: fsys, patterns: patterns}
+	//加载时就解析 模板有错误启动时就能发现
+	if _, err := t.load(set); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.sets[name] = set
+	t.mu.Unlock()
+	return nil
+}
+
+//Files 按文件缓存的模板 不用每次请求都解析
+func (t *HTMLTemplates) Files(patterns ...string) (*template.Template, error) {
+	key := "\x00" + strings.Join(patterns, "\x00")
+	t.mu.RLock()
+	set, ok := t.sets[key]
+	t.mu.RUnlock()
+	if !ok {
+		t.mu.Lock()
+		if set, ok = t.sets[key]; !ok {
+			set = &templateSet{patterns: patterns}
+			t.sets[key] = set
+		}
+		t.mu.Unlock()
+	}
+	return t.load(set)
+}
+
+func (t *HTMLTemplates) Instance(name string, data any) Render {
+	t.mu.RLock()
+	set, ok := t.sets[name]
+	if !ok {
+		set = t.sets[""]
+	}
+	t.mu.RUnlock()
+	if set == nil {
+		return &templateError{err: fmt.Errorf("html template %s not found", name)}
+	}
+	tmpl, err := t.load(set)
+	if err != nil {
+		return &templateError{err: err}
+	}
+	if ok {
+		name = tmpl.Name()
+	}
+	return &HTML{
+		Data:       data,
+		Name:       name,
+		Template:   tmpl,
+		IsTemplate: true,
+	}
+}
+
+//load 生产模式只解析一次 开发模式文件有变化时重新解析
+func (t *HTMLTemplates) load(set *templateSet) (*template.Template, error) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if set.tmpl != nil && !t.Reload {
+		return set.tmpl, nil
+	}
+	files, modTime, err := set.stat()
+	if err != nil {
+		return nil, err
+	}
+	if set.tmpl != nil && modTime.Equal(set.modTime) && sameFiles(files, set.files) {
+		return set.tmpl, nil
+	}
+	tmpl := template.New(path.Base(filepath.ToSlash(files[0]))).Funcs(t.FuncMap)
+	if set.fsys == nil {
+		tmpl, err = tmpl.ParseFiles(files...)
+	} else {
+		tmpl, err = tmpl.ParseFS(set.fsys, files...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	set.tmpl, set.files, set.modTime = tmpl, files, modTime
+	return tmpl, nil
+}
+
+//stat 展开通配符 返回匹配的文件和最后修改时间
+func (s *templateSet) stat() ([]string, time.Time, error) {
+	var files []string
+	var modTime time.Time
+	for _, pattern := range s.patterns {
+		var matches []string
+		var err error
+		if s.fsys == nil {
+			matches, err = filepath.Glob(pattern)
+		} else {
+			matches, err = fs.Glob(s.fsys, pattern)
+		}
+		if err != nil {
+			return nil, modTime, err
+		}
+		if len(matches) == 0 {
+			return nil, modTime, fmt.Errorf("html template: pattern matches no files: %#q", pattern)
+		}
+		for _, file := range matches {
+			var info fs.FileInfo
+			if s.fsys == nil {
+				info, err = os.Stat(file)
+			} else {
+				info, err = fs.Stat(s.fsys, file)
+			}
+			if err != nil {
+				return nil, modTime, err
+			}
+			if info.ModTime().After(modTime) {
+				modTime = info.ModTime()
+			}
+		}
+		files = append(files, matches...)
+	}
+	return files, modTime, nil
+}
+
+func sameFiles(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//templateError 模板找不到或者解析失败 渲染时返回错误
+type templateError struct {
+	err error
+}
+
+func (e *templateError) Render(w http.ResponseWriter, code int) error {
+	return e.err
+}
+
+func (e *templateError) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "text/html; charset=utf-8")
+}
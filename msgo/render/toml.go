@@ -0,0 +1,21 @@
+package render
+
+import (
+	"github.com/BurntSushi/toml"
+	"net/http"
+)
+
+//TOML Data 需要是结构体或者map
+type TOML struct {
+	Data any
+}
+
+func (t *TOML) Render(w http.ResponseWriter, code int) error {
+	t.WriteContentType(w)
+	w.WriteHeader(code)
+	return toml.NewEncoder(w).Encode(t.Data)
+}
+
+func (t *TOML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/toml; charset=utf-8")
+}
@@ -0,0 +1,25 @@
+package render
+
+import (
+	"gopkg.in/yaml.v3"
+	"net/http"
+)
+
+type YAML struct {
+	Data any
+}
+
+func (y *YAML) Render(w http.ResponseWriter, code int) error {
+	y.WriteContentType(w)
+	w.WriteHeader(code)
+	yamlData, err := yaml.Marshal(y.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(yamlData)
+	return err
+}
+
+func (y *YAML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/yaml; charset=utf-8")
+}
@@ -0,0 +1,127 @@
+package msgo
+
+import (
+	"embed"
+	"github.com/mszlu521/msgo/render"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextRenderFormats(t *testing.T) {
+	engine := New()
+	engine.SecureJsonPrefix(")]}',\n")
+	data := map[string]any{"name": "<b>ms</b>"}
+	msg := wrapperspb.String("goods")
+	g := engine.Group("render")
+	g.Get("/pure", func(ctx *Context) { _ = ctx.PureJSON(http.StatusOK, data) })
+	g.Get("/indented", func(ctx *Context) { _ = ctx.IndentedJSON(http.StatusOK, data) })
+	g.Get("/secure", func(ctx *Context) { _ = ctx.SecureJSON(http.StatusOK, []int{1, 2}) })
+	g.Get("/jsonp", func(ctx *Context) { _ = ctx.JSONP(http.StatusOK, data) })
+	g.Get("/yaml", func(ctx *Context) { _ = ctx.YAML(http.StatusOK, data) })
+	g.Get("/toml", func(ctx *Context) { _ = ctx.TOML(http.StatusOK, data) })
+	g.Get("/protobuf", func(ctx *Context) { _ = ctx.ProtoBuf(http.StatusOK, msg) })
+	g.Get("/msgpack", func(ctx *Context) { _ = ctx.MsgPack(http.StatusOK, []int{1}) })
+
+	tests := []struct {
+		path        string
+		contentType string
+		body        string
+	}{
+		{"/render/pure", "application/json; charset=utf-8", "{\"name\":\"<b>ms</b>\"}\n"},
+		{"/render/indented", "application/json; charset=utf-8", "{\n    \"name\": \"\\u003cb\\u003ems\\u003c/b\\u003e\"\n}"},
+		{"/render/secure", "application/json; charset=utf-8", ")]}',\n[1,2]"},
+		{"/render/jsonp?callback=alert(1)%3Bcb", "application/javascript; charset=utf-8",
+			"/**/ typeof alert1cb === 'function' && alert1cb({\"name\":\"\\u003cb\\u003ems\\u003c/b\\u003e\"});"},
+		{"/render/jsonp", "application/json; charset=utf-8", "{\"name\":\"\\u003cb\\u003ems\\u003c/b\\u003e\"}"},
+		{"/render/yaml", "application/yaml; charset=utf-8", "name: <b>ms</b>\n"},
+		{"/render/toml", "application/toml; charset=utf-8", "name = \"<b>ms</b>\"\n"},
+		{"/render/msgpack", "application/msgpack", "\x91\x01"},
+	}
+	for _, tt := range tests {
+		w := performRequest(engine, http.MethodGet, tt.path)
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("%s: content type %q", tt.path, ct)
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: body %q", tt.path, w.Body.String())
+		}
+	}
+
+	w := performRequest(engine, http.MethodGet, "/render/protobuf")
+	var got wrapperspb.StringValue
+	if err := proto.Unmarshal(w.Body.Bytes(), &got); err != nil || got.Value != "goods" {
+		t.Fatalf("protobuf: %v %v", got.Value, err)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/x-protobuf") {
+		t.Fatalf("protobuf content type %q", w.Header().Get("Content-Type"))
+	}
+}
+
+//go:embed testdata/templates
+var testTemplates embed.FS
+
+func TestTemplateSets(t *testing.T) {
+	engine := New()
+	engine.LoadTemplateSet("user", "testdata/templates/base.html", "testdata/templates/user.html")
+	engine.LoadTemplateSetFS("goods", testTemplates, "testdata/templates/base.html", "testdata/templates/goods.html")
+	g := engine.Group("page")
+	g.Get("/user", func(ctx *Context) {
+		_ = ctx.Template("user", map[string]any{"Title": "U", "Name": "ms"})
+	})
+	g.Get("/goods", func(ctx *Context) {
+		_ = ctx.Template("goods", map[string]any{"Title": "G", "Items": []int{1, 2}})
+	})
+	g.Get("/missing", func(ctx *Context) {
+		if err := ctx.Template("missing", nil); err != nil {
+			ctx.W.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	if body := performRequest(engine, http.MethodGet, "/page/user").Body.String(); body != "<html><title>U</title><body>user ms</body></html>\n" {
+		t.Fatalf("user: %q", body)
+	}
+	if body := performRequest(engine, http.MethodGet, "/page/goods").Body.String(); body != "<html><title>G</title><body>goods 2</body></html>\n" {
+		t.Fatalf("goods: %q", body)
+	}
+	if code := performRequest(engine, http.MethodGet, "/page/missing").Code; code != http.StatusInternalServerError {
+		t.Fatalf("missing template: %d", code)
+	}
+}
+
+func TestTemplateReload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "index.html")
+	write := func(content string, modTime time.Time) {
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_ = os.Chtimes(file, modTime, modTime)
+	}
+	now := time.Now()
+	for _, reload := range []bool{false, true} {
+		write(`{{define "index"}}v1{{end}}`, now)
+		templates := render.NewHTMLTemplates(nil)
+		templates.Reload = reload
+		if err := templates.AddFromFiles("", filepath.Join(dir, "*.html")); err != nil {
+			t.Fatal(err)
+		}
+		engine := New()
+		engine.HTMLRender = templates
+		engine.Group("page").Get("/index", func(ctx *Context) {
+			_ = ctx.Template("index", nil)
+		})
+		write(`{{define "index"}}v2{{end}}`, now.Add(time.Second))
+		want := "v1"
+		if reload {
+			want = "v2"
+		}
+		if body := performRequest(engine, http.MethodGet, "/page/index").Body.String(); body != want {
+			t.Fatalf("reload %v: %q", reload, body)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package msgo
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestResponseWriterStatusSize(t *testing.T) {
+	engine := New()
+	var status, size int
+	var written bool
+	engine.UseHandler(func(ctx *Context) {
+		ctx.Next()
+		status = ctx.Writer().Status()
+		size = ctx.Writer().Size()
+		written = ctx.Writer().Written()
+	})
+	g := engine.Group("user")
+	g.Get("/fprintf", func(ctx *Context) {
+		ctx.W.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(ctx.W, "%s info", "com")
+	})
+	g.Get("/header", func(ctx *Context) {
+		ctx.W.WriteHeader(http.StatusAccepted)
+	})
+
+	w := performRequest(engine, http.MethodGet, "/user/fprintf")
+	if w.Code != http.StatusCreated || status != http.StatusCreated || size != 8 || !written {
+		t.Fatalf("got %d %d %d %v", w.Code, status, size, written)
+	}
+	//只设置了状态码的 请求结束时才发送响应头 中间件里还可以改
+	w = performRequest(engine, http.MethodGet, "/user/header")
+	if w.Code != http.StatusAccepted || status != http.StatusAccepted || written {
+		t.Fatalf("got %d %d %v", w.Code, status, written)
+	}
+}
@@ -0,0 +1,144 @@
+package msgo
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+//Route 注册的路由 可以起名字用来反向生成URL
+// g.Get("/get/:id", h).Name("user.get")
+type Route struct {
+	engine *Engine
+	Method string
+	Path   string
+}
+
+func (r *Route) Name(name string) *Route {
+	if _, ok := r.engine.namedRoutes[name]; ok {
+		panic(fmt.Sprintf("有重复的路由名字: %s", name))
+	}
+	r.engine.namedRoutes[name] = r
+	return r
+}
+
+//MaxBodyBytes 单独设置这个路由的请求体大小 覆盖引擎的 MaxBodyBytes 小于等于0表示不限制
+func (r *Route) MaxBodyBytes(n int64) *Route {
+	group := r.engine.groupMap[r.Path][r.Method]
+	if _, ok := group.bodyLimitMap[r.Path]; !ok {
+		group.bodyLimitMap[r.Path] = make(map[string]int64)
+	}
+	group.bodyLimitMap[r.Path][r.Method] = n
+	return r
+}
+
+//URL 根据路由名字生成地址 pairs是参数名和值交替出现
+// e.URL("user.get", "id", 7) -> /user/get/7
+//路径中用不到的参数拼到查询字符串中，缺少路径参数返回错误
+func (e *Engine) URL(name string, pairs ...any) (string, error) {
+	route, ok := e.namedRoutes[name]
+	if !ok {
+		return "", fmt.Errorf("route %s not found", name)
+	}
+	if len(pairs)%2 != 0 {
+		return "", errors.New("url params must be key value pairs")
+	}
+	values := make(map[string]string, len(pairs)/2)
+	keys := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key := fmt.Sprint(pairs[i])
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = fmt.Sprint(pairs[i+1])
+	}
+	strs := strings.Split(route.Path, "/")
+	for i, name := range strs {
+		nType := segmentType(name)
+		if nType == static {
+			continue
+		}
+		key := paramKey(name)
+		value, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("route %s: missing param %s", route.Path, key)
+		}
+		delete(values, key)
+		if nType == catchAll {
+			//匹配剩余的 每一段分别转义 保留 /
+			parts := strings.Split(strings.TrimPrefix(value, "/"), "/")
+			for j, part := range parts {
+				parts[j] = url.PathEscape(part)
+			}
+			strs[i] = strings.Join(parts, "/")
+			continue
+		}
+		if value == "" {
+			return "", fmt.Errorf("route %s: param %s is empty", route.Path, key)
+		}
+		strs[i] = url.PathEscape(value)
+	}
+	path := strings.Join(strs, "/")
+	query := url.Values{}
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			query.Add(key, value)
+		}
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return path, nil
+}
+
+//RouteInfo 注册的路由信息
+type RouteInfo struct {
+	Method          string
+	Path            string
+	Handler         string
+	HandlerFunc     HandlerFunc
+	MiddlewareCount int
+}
+
+type RoutesInfo []RouteInfo
+
+//Routes 所有注册的路由 按路径和请求方式排序
+//中间件个数包括全局、组(含父组)和路由级别的
+func (e *Engine) Routes() RoutesInfo {
+	routes := make(RoutesInfo, 0)
+	for name, methods := range e.groupMap {
+		for method, group := range methods {
+			handler := group.handleFuncMap[name][method]
+			count := len(e.middles) + len(group.appendMiddlewares(nil)) + len(group.middlewaresFuncMap[name][method])
+			routes = append(routes, RouteInfo{
+				Method:          method,
+				Path:            name,
+				Handler:         nameOfFunction(handler),
+				HandlerFunc:     handler,
+				MiddlewareCount: count,
+			})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+	return routes
+}
+
+func (e *Engine) printRoutes() {
+	for _, route := range e.Routes() {
+		fmt.Fprintf(DefaultWriter, "[msgo-debug] %-7s %-40s --> %s (%d middlewares)\n",
+			route.Method, route.Path, route.Handler, route.MiddlewareCount)
+	}
+}
+
+func nameOfFunction(f any) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
@@ -3,9 +3,9 @@ package rpc
 import (
 	"bufio"
 	"bytes"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/mszlu521/msgo/codec"
 	"io"
 	"log"
 	"net/http"
@@ -55,7 +55,10 @@ func (c *MsHttpClient) FormRequest(method string, url string, args map[string]an
 }
 
 func (c *MsHttpClient) JsonRequest(method string, url string, args map[string]any) (*http.Request, error) {
-	jsonStr, _ := json.Marshal(args)
+	jsonStr, err := codec.JSON.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
 	req, err := http.NewRequest(method, url, bytes.NewReader(jsonStr))
 	if err != nil {
 		return nil, err
@@ -90,7 +93,10 @@ func (c *MsHttpClientSession) PostForm(url string, args map[string]any) ([]byte,
 }
 
 func (c *MsHttpClientSession) PostJson(url string, args map[string]any) ([]byte, error) {
-	marshal, _ := json.Marshal(args)
+	marshal, err := codec.JSON.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
 	request, err := http.NewRequest("POST", url, bytes.NewReader(marshal))
 	if err != nil {
 		return nil, err
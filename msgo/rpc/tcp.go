@@ -6,9 +6,9 @@ import (
 	"context"
 	"encoding/binary"
 	"encoding/gob"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/mszlu521/msgo/codec"
 	"github.com/mszlu521/msgo/register"
 	"golang.org/x/time/rate"
 	"google.golang.org/protobuf/proto"
@@ -210,6 +210,23 @@ type MsTcpConn struct {
 	rspChan chan *MsRpcResponse
 }
 
+//toStructValue protobuf序列化时 Data 先通过json转成 structpb
+func toStructValue(data any) (*structpb.Value, error) {
+	marshal, err := codec.JSON.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]any)
+	if err = codec.JSON.Unmarshal(marshal, &m); err != nil {
+		return nil, err
+	}
+	value, err := structpb.NewStruct(m)
+	if err != nil {
+		return nil, err
+	}
+	return structpb.NewStructValue(value), nil
+}
+
 func (c MsTcpConn) Send(rsp *MsRpcResponse) error {
 	if rsp.Code != 200 {
 		//进行默认的数据发送
@@ -240,14 +257,10 @@ func (c MsTcpConn) Send(rsp *MsRpcResponse) error {
 		pRsp.Code = int32(rsp.Code)
 		pRsp.Msg = rsp.Msg
 		pRsp.RequestId = rsp.RequestId
-		//value, err := structpb.
-		//	log.Println(err)
-		m := make(map[string]any)
-		marshal, _ := json.Marshal(rsp.Data)
-		_ = json.Unmarshal(marshal, &m)
-		value, err := structpb.NewStruct(m)
-		log.Println(err)
-		pRsp.Data = structpb.NewStructValue(value)
+		pRsp.Data, err = toStructValue(rsp.Data)
+		if err != nil {
+			return err
+		}
 		body, err = se.Serialize(pRsp)
 	} else {
 		body, err = se.Serialize(rsp)
@@ -718,9 +731,15 @@ func (c *MsTcpClient) readHandle(rspChan chan *MsRpcResponse) {
 			if msg.Header.SerializeType == ProtoBuff {
 				rsp := msg.Data.(*Response)
 				asInterface := rsp.Data.AsInterface()
-				marshal, _ := json.Marshal(asInterface)
 				rsp1 := &MsRpcResponse{}
-				json.Unmarshal(marshal, rsp1)
+				marshal, err := codec.JSON.Marshal(asInterface)
+				if err == nil {
+					err = codec.JSON.Unmarshal(marshal, rsp1)
+				}
+				if err != nil {
+					rsp1.Code = 500
+					rsp1.Msg = err.Error()
+				}
 				rspChan <- rsp1
 			} else {
 				rsp := msg.Data.(*MsRpcResponse)
@@ -0,0 +1,196 @@
+package msgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const defaultShutdownTimeout = 10 * time.Second
+
+//Server Engine 启动之后的句柄 用来关闭服务
+type Server struct {
+	engine      *Engine
+	httpServer  *http.Server
+	listener    net.Listener
+	done        chan struct{}
+	serveErr    error
+	once        sync.Once
+	shutdownErr error
+}
+
+//Start 启动服务但不阻塞 返回的 Server 用来 Shutdown(ctx) 或者通过 Done 等待服务结束
+func (e *Engine) Start(addr string) (*Server, error) {
+	return e.start(addr, func(s *Server) error {
+		return s.httpServer.Serve(s.listener)
+	})
+}
+
+//StartTLS 同 Start 使用https
+func (e *Engine) StartTLS(addr, certFile, keyFile string) (*Server, error) {
+	return e.start(addr, func(s *Server) error {
+		return s.httpServer.ServeTLS(s.listener, certFile, keyFile)
+	})
+}
+
+func (e *Engine) start(addr string, serve func(s *Server) error) (*Server, error) {
+	if err := e.createRegisterCli(); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if IsDebugging() {
+		e.printRoutes()
+		fmt.Fprintf(DefaultWriter, "[msgo-debug] Listening and serving HTTP on %s\n", listener.Addr())
+	}
+	s := &Server{
+		engine:     e,
+		httpServer: &http.Server{Addr: addr, Handler: e},
+		listener:   listener,
+		done:       make(chan struct{}),
+	}
+	for _, hook := range e.startHooks {
+		if err := hook(); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+	if err := e.registerService(listener.Addr()); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	e.serverLock.Lock()
+	e.server = s
+	e.serverLock.Unlock()
+	go func() {
+		defer close(s.done)
+		if err := serve(s); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.serveErr = err
+		}
+	}()
+	return s, nil
+}
+
+//registerService 配置了服务名就在监听成功后把自己注册到注册中心 关闭时自动下线
+//没有配置Host和Port的 用实际监听的地址
+func (e *Engine) registerService(addr net.Addr) error {
+	if e.RegisterCli == nil || e.RegisterOption.ServiceName == "" {
+		return nil
+	}
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if ok && e.RegisterOption.Port == 0 {
+		e.RegisterOption.Port = tcpAddr.Port
+	}
+	if ok && e.RegisterOption.Host == "" {
+		e.RegisterOption.Host = hostIP(tcpAddr.IP)
+	}
+	return e.RegisterCli.RegisterService(e.RegisterOption.ServiceName, e.RegisterOption.Host, e.RegisterOption.Port)
+}
+
+//hostIP 监听的是 0.0.0.0 这种地址时 取本机第一个非回环的IPv4
+func hostIP(ip net.IP) string {
+	if !ip.IsUnspecified() {
+		return ip.String()
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err == nil {
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+				return ipNet.IP.String()
+			}
+		}
+	}
+	return "127.0.0.1"
+}
+
+//Addr 实际监听的地址 addr 为 :0 时可以拿到分配的端口
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+//Done 服务停止处理请求之后关闭
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+//Err 服务异常退出的错误 正常关闭为nil
+func (s *Server) Err() error {
+	<-s.done
+	return s.serveErr
+}
+
+//Shutdown 优雅关闭 先从注册中心下线，不再接收新的连接，等待正在处理的请求完成，
+//再执行 OnShutdown 注册的钩子，最后关闭注册中心的客户端。多次调用只执行一次
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.once.Do(func() {
+		e := s.engine
+		if e.RegisterCli != nil && e.RegisterOption.ServiceName != "" {
+			err := e.RegisterCli.DeregisterService(e.RegisterOption.ServiceName, e.RegisterOption.Host, e.RegisterOption.Port)
+			s.setShutdownErr(err)
+		}
+		s.setShutdownErr(s.httpServer.Shutdown(ctx))
+		for _, hook := range e.shutdownHooks {
+			s.setShutdownErr(hook())
+		}
+		if e.RegisterCli != nil {
+			s.setShutdownErr(e.RegisterCli.Close())
+		}
+	})
+	return s.shutdownErr
+}
+
+//setShutdownErr 关闭过程中出错也继续往下执行 返回第一个错误 其余的打印出来
+func (s *Server) setShutdownErr(err error) {
+	if err == nil {
+		return
+	}
+	if s.shutdownErr == nil {
+		s.shutdownErr = err
+		return
+	}
+	log.Println(err)
+}
+
+//Shutdown 关闭正在运行的服务
+func (e *Engine) Shutdown(ctx context.Context) error {
+	e.serverLock.Lock()
+	server := e.server
+	e.serverLock.Unlock()
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+//waitShutdown 等待退出信号或者服务异常退出，然后优雅关闭
+func (e *Engine) waitShutdown(s *Server) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	select {
+	case <-ctx.Done():
+	case <-s.Done():
+		if err := s.Err(); err != nil {
+			log.Println(err)
+		}
+	}
+	stop()
+	timeout := e.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		log.Println(err)
+	}
+}
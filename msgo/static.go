@@ -0,0 +1,160 @@
+package msgo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+//onlyFilesFS 不允许列出目录 目录下没有index.html时当作不存在
+type onlyFilesFS struct {
+	fs http.FileSystem
+}
+
+func (o onlyFilesFS) Open(name string) (http.File, error) {
+	f, err := o.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if stat.IsDir() {
+		index, err := o.fs.Open(path.Join(name, "index.html"))
+		if err != nil {
+			f.Close()
+			return nil, os.ErrNotExist
+		}
+		index.Close()
+	}
+	return f, nil
+}
+
+//OnlyFilesFS 包装文件系统 关闭目录列表
+// g.StaticFS("/assets", msgo.OnlyFilesFS(http.FS(embedFS)))
+func OnlyFilesFS(fs http.FileSystem) http.FileSystem {
+	return onlyFilesFS{fs: fs}
+}
+
+//Dir 本地目录 listDirectory 控制是否允许列出目录
+func Dir(root string, listDirectory bool) http.FileSystem {
+	fs := http.Dir(root)
+	if listDirectory {
+		return fs
+	}
+	return OnlyFilesFS(fs)
+}
+
+//Static 把本地目录挂到路由上 默认不列出目录
+// g.Static("/assets", "./assets")  -> /user/assets/css/app.css
+func (r *routerGroup) Static(prefix, root string) *Route {
+	return r.StaticFS(prefix, Dir(root, false))
+}
+
+//StaticFS 挂载任意文件系统 embed.FS 用 http.FS 转一下
+// g.StaticFS("/assets", http.FS(assets))
+func (r *routerGroup) StaticFS(prefix string, fs http.FileSystem) *Route {
+	if strings.Contains(prefix, ":") || strings.Contains(prefix, "*") {
+		panic("URL parameters can not be used when serving a static folder")
+	}
+	handler := r.createStaticHandler(prefix, fs)
+	return r.Get(path.Join(prefix, "/**filepath"), handler)
+}
+
+//StaticFile 单个文件 g.StaticFile("/favicon.ico", "./resources/favicon.ico")
+func (r *routerGroup) StaticFile(relativePath, filepath string) *Route {
+	if strings.Contains(relativePath, ":") || strings.Contains(relativePath, "*") {
+		panic("URL parameters can not be used when serving a static file")
+	}
+	dir, file := path.Split(filepath)
+	fs := http.Dir(dir)
+	etags := &etagCache{}
+	return r.Get(relativePath, func(ctx *Context) {
+		serveStatic(ctx, fs, "/"+file, etags, func() {
+			ctx.File(filepath)
+		})
+	})
+}
+
+func (r *routerGroup) createStaticHandler(prefix string, fs http.FileSystem) HandlerFunc {
+	absolutePath := joinPaths(r.prefix, prefix)
+	fileServer := http.StripPrefix(absolutePath, http.FileServer(fs))
+	etags := &etagCache{}
+	return func(ctx *Context) {
+		file := "/" + ctx.Param("filepath")
+		serveStatic(ctx, fs, file, etags, func() {
+			fileServer.ServeHTTP(ctx.W, ctx.R)
+		})
+	}
+}
+
+//serveStatic 检查路径和文件 设置ETag之后交给net/http处理 If-None-Match If-Modified-Since 和 Range
+func serveStatic(ctx *Context, fs http.FileSystem, file string, etags *etagCache, serve func()) {
+	//防止 ../ 访问到目录之外的文件
+	if containsDotDot(file) {
+		ctx.String(http.StatusBadRequest, "invalid path")
+		return
+	}
+	f, err := fs.Open(file)
+	if err != nil {
+		ctx.engine.serveNoRoute(ctx)
+		return
+	}
+	stat, err := f.Stat()
+	if err == nil && !stat.IsDir() {
+		ctx.W.Header().Set("ETag", etags.get(file, f, stat))
+	}
+	f.Close()
+	if err != nil {
+		ctx.engine.serveNoRoute(ctx)
+		return
+	}
+	ctx.StatusCode = http.StatusOK
+	serve()
+}
+
+func containsDotDot(v string) bool {
+	if !strings.Contains(v, "..") {
+		return false
+	}
+	for _, ent := range strings.FieldsFunc(v, isSlashRune) {
+		if ent == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+func isSlashRune(r rune) bool {
+	return r == '/' || r == '\\'
+}
+
+//etagCache 有修改时间的用 修改时间+大小 做ETag
+//embed.FS 这种没有修改时间的 按内容算hash 内容不会变 算一次缓存起来
+type etagCache struct {
+	etags sync.Map
+}
+
+func (c *etagCache) get(name string, f http.File, stat fs.FileInfo) string {
+	if !stat.ModTime().IsZero() {
+		return fmt.Sprintf(`W/"%x-%x"`, stat.ModTime().UnixNano(), stat.Size())
+	}
+	if etag, ok := c.etags.Load(name); ok {
+		return etag.(string)
+	}
+	h := fnv.New64a()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	etag := fmt.Sprintf(`"%x-%x"`, h.Sum64(), stat.Size())
+	c.etags.Store(name, etag)
+	return etag
+}
@@ -0,0 +1,81 @@
+package msgo
+
+import (
+	"embed"
+	"errors"
+	msLog "github.com/mszlu521/msgo/log"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+//go:embed testdata/assets
+var testAssets embed.FS
+
+func TestStatic(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Static("/assets", "testdata/assets")
+	sub, _ := fs.Sub(testAssets, "testdata/assets")
+	g.StaticFS("/embed", http.FS(sub))
+	g.StaticFile("/app.css", "testdata/assets/css/app.css")
+
+	for _, path := range []string{"/user/assets/css/app.css", "/user/embed/css/app.css", "/user/app.css"} {
+		w := performRequest(engine, http.MethodGet, path)
+		if w.Code != http.StatusOK || w.Body.String() != "body{}\n" {
+			t.Fatalf("%s: got %d %q", path, w.Code, w.Body.String())
+		}
+		etag := w.Header().Get("ETag")
+		if etag == "" {
+			t.Fatalf("%s: want ETag", path)
+		}
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		if w.Code != http.StatusNotModified {
+			t.Fatalf("%s: want 304, got %d", path, w.Code)
+		}
+	}
+
+	w := performRequest(engine, http.MethodGet, "/user/assets/none.css")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("got %d", w.Code)
+	}
+	//没有index.html的目录不能列出
+	w = performRequest(engine, http.MethodGet, "/user/assets/empty/")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("got %d", w.Code)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/user/assets/css/app.css", nil)
+	r.URL.Path = "/user/assets/../../ms.go"
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("got %d", w.Code)
+	}
+}
+
+func TestStaticNoRoutePanic(t *testing.T) {
+	engine := New()
+	engine.Logger = msLog.Default()
+	engine.Use(Recovery)
+	var ctxs []*Context
+	engine.UseHandler(func(ctx *Context) {
+		ctxs = append(ctxs, ctx)
+		ctx.Next()
+	})
+	engine.NoRoute(func(ctx *Context) {
+		panic(errors.New("not found"))
+	})
+	engine.Group("user").Static("/assets", "testdata/assets")
+	performRequest(engine, http.MethodGet, "/user/assets/none.css")
+	noRoute := engine.noRoute
+	for _, ctx := range ctxs {
+		//处理链要换回来 不能和 e.noRoute 共用底层数组
+		if cap(ctx.handlers) > 0 && &ctx.handlers[:1][0] == &noRoute[:1][0] {
+			t.Fatal("pooled context still uses the engine noRoute chain")
+		}
+	}
+}
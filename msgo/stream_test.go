@@ -0,0 +1,61 @@
+package msgo
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextSSEvent(t *testing.T) {
+	engine := New()
+	var log bytes.Buffer
+	engine.Use(func(next HandlerFunc) HandlerFunc {
+		return LoggingWithConfig(LoggingConfig{out: &log}, next)
+	})
+	engine.Group("order").Get("/status", func(ctx *Context) {
+		_ = ctx.SSEvent("status", map[string]any{"id": 1, "state": "paid"})
+		_ = ctx.SSEvent("", "line1\nline2")
+		//单独的 \r 也是换行 不能注入 id 字段
+		_ = ctx.SSEvent("", "x\rid: 9\r\ny")
+	})
+	w := performRequest(engine, http.MethodGet, "/order/status")
+	want := "event: status\ndata: {\"id\":1,\"state\":\"paid\"}\n\n" +
+		"data: line1\ndata: line2\n\n" +
+		"data: x\ndata: id: 9\ndata: y\n\n"
+	if w.Body.String() != want {
+		t.Fatalf("body = %q", w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" || !w.Flushed {
+		t.Fatalf("content type %q, flushed %v", ct, w.Flushed)
+	}
+	if !bytes.Contains(log.Bytes(), []byte("/order/status")) {
+		t.Fatalf("request not logged: %s", log.String())
+	}
+}
+
+func TestContextStream(t *testing.T) {
+	engine := New()
+	var gone bool
+	var steps int
+	reqCtx, cancel := context.WithCancel(context.Background())
+	engine.Group("order").Get("/stream", func(ctx *Context) {
+		gone = ctx.Stream(func(w io.Writer) bool {
+			steps++
+			_, _ = io.WriteString(w, "chunk\n")
+			if steps == 2 {
+				//客户端断开
+				cancel()
+			}
+			return true
+		})
+	})
+	r := httptest.NewRequest(http.MethodGet, "/order/stream", nil).WithContext(reqCtx)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, r)
+	if !gone || steps != 2 || w.Body.String() != "chunk\nchunk\n" || !w.Flushed {
+		t.Fatalf("gone %v, steps %d, body %q", gone, steps, w.Body.String())
+	}
+}
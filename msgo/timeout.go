@@ -0,0 +1,130 @@
+package msgo
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const timeoutMsg = "Service Unavailable"
+
+//Timeout 请求超时 请求的context到时间取消，处理函数还没返回的直接响应503
+//处理函数的输出先写到缓冲区 按时完成才发送，所以不适合流式响应
+//超时之后仍然会等处理函数返回再结束请求(Context是复用的)，处理函数应该监听 ctx.Done()
+func Timeout(timeout time.Duration) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			c, cancel := context.WithTimeout(ctx.R.Context(), timeout)
+			defer cancel()
+			w, r := ctx.W, ctx.R
+			tw := &timeoutWriter{header: make(http.Header)}
+			ctx.W = tw
+			ctx.R = r.WithContext(c)
+			done := make(chan struct{})
+			panicChan := make(chan any, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicChan <- p
+					}
+					close(done)
+				}()
+				next(ctx)
+			}()
+			select {
+			case <-done:
+				ctx.W, ctx.R = w, r
+				select {
+				case p := <-panicChan:
+					//放到当前协程中panic 让Recovery处理
+					panic(p)
+				default:
+				}
+				tw.writeTo(w)
+			case <-c.Done():
+				tw.timeout()
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				w.Header().Set("Content-Length", strconv.Itoa(len(timeoutMsg)))
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(timeoutMsg))
+				if flusher, ok := w.(http.Flusher); ok {
+					flusher.Flush()
+				}
+				<-done
+				ctx.W, ctx.R = w, r
+				ctx.StatusCode = http.StatusServiceUnavailable
+				ctx.Abort()
+				select {
+				case p := <-panicChan:
+					//超时之后的panic也要交给Recovery 记录日志
+					panic(p)
+				default:
+				}
+			}
+		}
+	}
+}
+
+//timeoutWriter 缓存处理函数的响应 超时之后的写入都丢弃
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	buf         bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(data []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	return tw.buf.Write(data)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.writeHeaderLocked(code)
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	tw.wroteHeader = true
+	tw.code = code
+}
+
+func (tw *timeoutWriter) timeout() {
+	tw.mu.Lock()
+	tw.timedOut = true
+	tw.mu.Unlock()
+}
+
+func (tw *timeoutWriter) writeTo(w http.ResponseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	dst := w.Header()
+	for k, vv := range tw.header {
+		dst[k] = vv
+	}
+	if tw.wroteHeader {
+		w.WriteHeader(tw.code)
+	}
+	if tw.buf.Len() > 0 {
+		w.Write(tw.buf.Bytes())
+	}
+}
@@ -0,0 +1,90 @@
+package msgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func waitDownstream(c context.Context) error {
+	select {
+	case <-c.Done():
+		return c.Err()
+	case <-time.After(time.Second):
+		return nil
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Use(Timeout(50 * time.Millisecond))
+	var downstreamErr error
+	g.Get("/slow", func(ctx *Context) {
+		//Context 直接当作 context.Context 传给下游
+		downstreamErr = waitDownstream(ctx)
+		ctx.String(http.StatusOK, "slow")
+	})
+	g.Get("/fast", func(ctx *Context) {
+		ctx.W.Header().Set("X-Fast", "1")
+		ctx.String(http.StatusCreated, "fast")
+	})
+
+	w := performRequest(engine, http.MethodGet, "/user/slow")
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != timeoutMsg {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	if downstreamErr != context.DeadlineExceeded {
+		t.Fatalf("downstream should be cancelled, got %v", downstreamErr)
+	}
+	w = performRequest(engine, http.MethodGet, "/user/fast")
+	if w.Code != http.StatusCreated || w.Body.String() != "fast" || w.Header().Get("X-Fast") != "1" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestTimeoutPanicAfterDeadline(t *testing.T) {
+	engine := New()
+	var recovered any
+	engine.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			defer func() {
+				recovered = recover()
+			}()
+			next(ctx)
+		}
+	})
+	g := engine.Group("user")
+	g.Use(Timeout(20 * time.Millisecond))
+	g.Get("/panic", func(ctx *Context) {
+		<-ctx.Done()
+		panic(errors.New("after deadline"))
+	})
+	w := performRequest(engine, http.MethodGet, "/user/panic")
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != timeoutMsg {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	if err, ok := recovered.(error); !ok || err.Error() != "after deadline" {
+		t.Fatalf("panic should be re-raised, got %v", recovered)
+	}
+}
+
+func TestContextValue(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Get("/info", func(ctx *Context) {
+		ctx.Set("user", "mszlu")
+		var c context.Context = ctx
+		if c.Value("user") != "mszlu" || c.Err() != nil {
+			ctx.String(http.StatusInternalServerError, "bad context")
+			return
+		}
+		ctx.String(http.StatusOK, "ok")
+	})
+	w := performRequest(engine, http.MethodGet, "/user/info")
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+}
@@ -1,84 +1,287 @@
 package msgo
 
-import "strings"
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
 
+//Param 路由中匹配到的路径参数 比如 /user/get/:id 中的id
+type Param struct {
+	Key   string
+	Value string
+}
+
+type Params []Param
+
+func (ps Params) Get(name string) (string, bool) {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+func (ps Params) ByName(name string) string {
+	value, _ := ps.Get(name)
+	return value
+}
+
+//paramKey :id -> id  *name -> name  **path -> path 没有名字的 * 和 ** 用本身做key
+func paramKey(name string) string {
+	switch {
+	case strings.HasPrefix(name, ":"):
+		return name[1:]
+	case strings.HasPrefix(name, "**"):
+		if len(name) > 2 {
+			return name[2:]
+		}
+	case strings.HasPrefix(name, "*"):
+		if len(name) > 1 {
+			return name[1:]
+		}
+	}
+	return name
+}
+
+type nodeType uint8
+
+const (
+	static   nodeType = iota // user
+	param                    // :id
+	wildcard                 // * 匹配一段
+	catchAll                 // ** 匹配剩余所有
+)
+
+//匹配优先级 静态 > :参数 > * > **，和注册顺序无关
 type treeNode struct {
 	name       string
+	nType      nodeType
+	key        string
 	children   []*treeNode
+	paramChild *treeNode
+	wildChild  *treeNode
+	catchAll   *treeNode
 	routerName string
 	isEnd      bool
+	//methods 这个路由注册过的请求方式 包括ANY
+	methods []string
+}
+
+func segmentType(name string) nodeType {
+	switch {
+	case strings.HasPrefix(name, ":"):
+		return param
+	case strings.HasPrefix(name, "**"):
+		return catchAll
+	case strings.HasPrefix(name, "*"):
+		return wildcard
+	}
+	return static
+}
+
+//countParams 路由中参数的个数，用来预先分配Context中params的容量
+func countParams(path string) int {
+	n := 0
+	for _, name := range strings.Split(path, "/") {
+		if segmentType(name) != static {
+			n++
+		}
+	}
+	return n
 }
 
 //put path: /user/get/:id
+//同一位置上名字不同的参数 比如 /a/:id 和 /a/:name 注册时直接panic
+//返回路由的最后一个节点
 
-func (t *treeNode) Put(path string) {
-	root := t
+func (t *treeNode) Put(path string) *treeNode {
 	strs := strings.Split(path, "/")
+	routerName := ""
 	for index, name := range strs {
 		if index == 0 {
 			continue
 		}
-		children := t.children
-		isMatch := false
-		for _, node := range children {
-			if node.name == name {
-				isMatch = true
-				t = node
-				break
-			}
+		routerName += "/" + name
+		nType := segmentType(name)
+		if nType == catchAll && index != len(strs)-1 {
+			panic(fmt.Sprintf("catch-all '%s' must be the last segment in path '%s'", name, path))
 		}
-		if !isMatch {
-			isEnd := false
-			if index == len(strs)-1 {
-				isEnd = true
-			}
-			node := &treeNode{name: name, children: make([]*treeNode, 0), isEnd: isEnd}
-			children = append(children, node)
-			t.children = children
-			t = node
+		var slot **treeNode
+		switch nType {
+		case param:
+			slot = &t.paramChild
+		case wildcard:
+			slot = &t.wildChild
+		case catchAll:
+			slot = &t.catchAll
+		}
+		if slot == nil {
+			t = t.staticChild(name, routerName)
+			continue
+		}
+		if *slot == nil {
+			*slot = &treeNode{name: name, nType: nType, key: paramKey(name), children: make([]*treeNode, 0), routerName: routerName}
+		} else if (*slot).name != name {
+			panic(fmt.Sprintf("route conflict: '%s' in path '%s' conflicts with existing '%s' in path '%s'",
+				name, path, (*slot).name, (*slot).routerName))
+		}
+		t = *slot
+	}
+	t.isEnd = true
+	return t
+}
+
+func (t *treeNode) addMethod(method string) {
+	for _, m := range t.methods {
+		if m == method {
+			return
 		}
 	}
-	t = root
+	t.methods = append(t.methods, method)
+}
+
+//handles 路由能否处理这个请求方式 method为空表示不区分 HEAD 可以用 GET 的处理
+func (t *treeNode) handles(method string) bool {
+	if method == "" {
+		return true
+	}
+	for _, m := range t.methods {
+		if m == ANY || m == method || (method == http.MethodHead && m == http.MethodGet) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *treeNode) staticChild(name string, routerName string) *treeNode {
+	for _, node := range t.children {
+		if node.name == name {
+			return node
+		}
+	}
+	node := &treeNode{name: name, nType: static, children: make([]*treeNode, 0), routerName: routerName}
+	t.children = append(t.children, node)
+	return node
 }
 
 //get path: /user/get/1
 // /hello
-func (t *treeNode) Get(path string) *treeNode {
-	strs := strings.Split(path, "/")
-	routerName := ""
-	for index, name := range strs {
-		if index == 0 {
-			continue
-		}
-		children := t.children
-		isMatch := false
-		for _, node := range children {
-			if node.name == name ||
-				node.name == "*" ||
-				strings.Contains(node.name, ":") {
-				isMatch = true
-				routerName += "/" + node.name
-				node.routerName = routerName
-				t = node
-				if index == len(strs)-1 {
-					return node
-				}
-				break
+//匹配到的路径参数追加到params中，查找过程不修改树，也不分配内存(params容量足够时)
+func (t *treeNode) Get(path string, params *Params) *treeNode {
+	return t.GetMethod(path, "", params)
+}
+
+//GetMethod 只匹配能处理method的路由 比如注册了 GET /user/:id 和 POST /user/info
+//GET /user/info 时静态路由没有GET 回溯到 /user/:id
+func (t *treeNode) GetMethod(path string, method string, params *Params) *treeNode {
+	if len(path) == 0 || path[0] != '/' {
+		return nil
+	}
+	return t.search(path[1:], method, params)
+}
+
+//search path是去掉了前面 / 的剩余部分 比如 get/1
+func (t *treeNode) search(path string, method string, params *Params) *treeNode {
+	name, rest, last := path, "", true
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		name, rest, last = path[:i], path[i+1:], false
+	}
+	for _, node := range t.children {
+		if node.name == name {
+			if n := node.next(rest, last, method, params); n != nil {
+				return n
 			}
+			break
 		}
-		if !isMatch {
-			for _, node := range children {
-				// /user/**
-				// /user/get/userInfo
-				// /user/aa/bb
-				if node.name == "**" {
-					routerName += "/" + node.name
-					node.routerName = routerName
-					return node
-				}
-			}
-
+	}
+	if name != "" {
+		if n := t.paramChild.matchSegment(name, rest, last, method, params); n != nil {
+			return n
+		}
+		if n := t.wildChild.matchSegment(name, rest, last, method, params); n != nil {
+			return n
 		}
 	}
+	if t.catchAll != nil && t.catchAll.handles(method) {
+		// /user/**
+		// /user/get/userInfo
+		// /user/aa/bb
+		if params != nil {
+			*params = append(*params, Param{Key: t.catchAll.key, Value: path})
+		}
+		return t.catchAll
+	}
 	return nil
 }
+
+func (t *treeNode) matchSegment(name string, rest string, last bool, method string, params *Params) *treeNode {
+	if t == nil {
+		return nil
+	}
+	if params != nil {
+		*params = append(*params, Param{Key: t.key, Value: name})
+	}
+	if n := t.next(rest, last, method, params); n != nil {
+		return n
+	}
+	//回溯 去掉这一段的参数
+	if params != nil {
+		*params = (*params)[:len(*params)-1]
+	}
+	return nil
+}
+
+func (t *treeNode) next(rest string, last bool, method string, params *Params) *treeNode {
+	if last {
+		if t.isEnd && t.handles(method) {
+			return t
+		}
+		return nil
+	}
+	return t.search(rest, method, params)
+}
+
+//matchAll 不区分请求方式 能匹配上path的所有路由 用来生成405和OPTIONS的Allow头
+func (t *treeNode) matchAll(path string, nodes []*treeNode) []*treeNode {
+	if len(path) == 0 || path[0] != '/' {
+		return nodes
+	}
+	return t.collect(path[1:], nodes)
+}
+
+func (t *treeNode) collect(path string, nodes []*treeNode) []*treeNode {
+	name, rest, last := path, "", true
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		name, rest, last = path[:i], path[i+1:], false
+	}
+	for _, node := range t.children {
+		if node.name == name {
+			nodes = node.collectNext(rest, last, nodes)
+			break
+		}
+	}
+	if name != "" {
+		if t.paramChild != nil {
+			nodes = t.paramChild.collectNext(rest, last, nodes)
+		}
+		if t.wildChild != nil {
+			nodes = t.wildChild.collectNext(rest, last, nodes)
+		}
+	}
+	if t.catchAll != nil {
+		nodes = append(nodes, t.catchAll)
+	}
+	return nodes
+}
+
+func (t *treeNode) collectNext(rest string, last bool, nodes []*treeNode) []*treeNode {
+	if last {
+		if t.isEnd {
+			return append(nodes, t)
+		}
+		return nodes
+	}
+	return t.collect(rest, nodes)
+}
@@ -13,12 +13,106 @@ func TestTreeNode(t *testing.T) {
 	root.Put("/user/create/aaa")
 	root.Put("/order/get/aaa")
 
-	node := root.Get("/user/get/1")
+	node := root.Get("/user/get/1", nil)
 	fmt.Println(node)
-	node = root.Get("/user/create/hello")
+	node = root.Get("/user/create/hello", nil)
 	fmt.Println(node)
-	node = root.Get("/user/create/aaa")
+	node = root.Get("/user/create/aaa", nil)
 	fmt.Println(node)
-	node = root.Get("/order/get/aaa")
+	node = root.Get("/order/get/aaa", nil)
 	fmt.Println(node)
 }
+
+func TestTreeNodeParams(t *testing.T) {
+	root := &treeNode{name: "/", children: make([]*treeNode, 0)}
+	root.Put("/user/get/:id")
+	root.Put("/user/:name/*action")
+	root.Put("/static/**filepath")
+
+	var params Params
+	node := root.Get("/user/get/1", &params)
+	if node == nil || params.ByName("id") != "1" {
+		t.Fatalf("want id=1, got %v", params)
+	}
+	params = params[:0]
+	node = root.Get("/user/mszlu/edit", &params)
+	if node == nil || params.ByName("name") != "mszlu" || params.ByName("action") != "edit" {
+		t.Fatalf("want name=mszlu action=edit, got %v", params)
+	}
+	params = params[:0]
+	node = root.Get("/static/css/app.css", &params)
+	if node == nil || params.ByName("filepath") != "css/app.css" {
+		t.Fatalf("want filepath=css/app.css, got %v", params)
+	}
+}
+
+func TestTreeNodePriority(t *testing.T) {
+	root := &treeNode{name: "/", children: make([]*treeNode, 0)}
+	root.Put("/user/**")
+	root.Put("/user/*")
+	root.Put("/user/:id")
+	root.Put("/user/info")
+	root.Put("/user/:id/orders")
+	root.Put("/user/info/detail")
+
+	tests := []struct {
+		path       string
+		routerName string
+	}{
+		{"/user/info", "/user/info"},
+		{"/user/1", "/user/:id"},
+		{"/user/1/orders", "/user/:id/orders"},
+		{"/user/info/orders", "/user/:id/orders"},
+		{"/user/info/detail", "/user/info/detail"},
+		{"/user/1/2/3", "/user/**"},
+	}
+	for _, tt := range tests {
+		node := root.Get(tt.path, nil)
+		if node == nil || node.routerName != tt.routerName {
+			t.Fatalf("%s: want %s, got %v", tt.path, tt.routerName, node)
+		}
+	}
+
+	var params Params
+	root.Get("/user/info/orders", &params)
+	if len(params) != 1 || params.ByName("id") != "info" {
+		t.Fatalf("backtracking should drop stale params, got %v", params)
+	}
+}
+
+func TestTreeNodeConflict(t *testing.T) {
+	tests := [][]string{
+		{"/a/:id", "/a/:name"},
+		{"/a/*x", "/a/*y"},
+		{"/a/**/b"},
+	}
+	for _, paths := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%v: want panic", paths)
+				}
+			}()
+			root := &treeNode{name: "/", children: make([]*treeNode, 0)}
+			for _, path := range paths {
+				root.Put(path)
+			}
+		}()
+	}
+}
+
+func TestTreeNodeGetAllocs(t *testing.T) {
+	root := &treeNode{name: "/", children: make([]*treeNode, 0)}
+	root.Put("/user/get/:id")
+	root.Put("/static/**filepath")
+	params := make(Params, 0, 1)
+	allocs := testing.AllocsPerRun(100, func() {
+		params = params[:0]
+		root.Get("/user/get/1", &params)
+		params = params[:0]
+		root.Get("/static/css/app.css", &params)
+	})
+	if allocs != 0 {
+		t.Fatalf("want 0 allocs, got %v", allocs)
+	}
+}
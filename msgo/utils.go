@@ -1,6 +1,7 @@
 package msgo
 
 import (
+	"path"
 	"strings"
 	"unicode"
 	"unsafe"
@@ -14,6 +15,18 @@ func SubStringLast(str string, substr string) string {
 	return str[index+len(substr):]
 }
 
+//joinPaths 拼接组前缀和路由 保留路由末尾的 /
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+	finalPath := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(finalPath, "/") {
+		return finalPath + "/"
+	}
+	return finalPath
+}
+
 func isASCII(s string) bool {
 	for i := 0; i < len(s); i++ {
 		if s[i] > unicode.MaxASCII {
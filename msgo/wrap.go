@@ -0,0 +1,60 @@
+package msgo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type contextKey struct{}
+
+//msgoContextKey 标准库中间件中通过 r.Context() 拿回 *Context
+var msgoContextKey = contextKey{}
+
+//WrapH 把标准库的 http.Handler 当作处理函数 比如 pprof promhttp
+// g.Get("/metrics", msgo.WrapH(promhttp.Handler()))
+func WrapH(h http.Handler) HandlerFunc {
+	return func(ctx *Context) {
+		h.ServeHTTP(ctx.W, ctx.R)
+	}
+}
+
+func WrapF(f http.HandlerFunc) HandlerFunc {
+	return func(ctx *Context) {
+		f(ctx.W, ctx.R)
+	}
+}
+
+//MiddlewareFromStd 适配标准库风格的中间件 func(http.Handler) http.Handler
+//中间件替换的 ResponseWriter 和 Request(比如otel加了span的context) 在后面的处理中生效
+//中间件没有调用 next 就返回 认为请求被拦截
+func MiddlewareFromStd(m func(http.Handler) http.Handler) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		handler := m(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context().Value(msgoContextKey).(*Context)
+			ctx.W = w
+			ctx.R = r
+			next(ctx)
+		}))
+		return func(ctx *Context) {
+			w, r := ctx.W, ctx.R
+			handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), msgoContextKey, ctx)))
+			ctx.W = w
+			ctx.R = r
+		}
+	}
+}
+
+//Mount 把 http.Handler 挂到前缀下 转发前去掉前缀 h 收到的是前缀之后的路径
+// g.Mount("/assets", http.FileServer(http.Dir("public")))  /assets/app.css -> /app.css
+//pprof 这种按完整路径匹配的不要用 Mount 用 WrapH 注册 g.Any("/debug/pprof/**", msgo.WrapH(http.DefaultServeMux))
+// engine 本身也是 http.Handler 挂到别的mux下用 http.StripPrefix
+func (r *routerGroup) Mount(prefix string, h http.Handler) *Route {
+	if strings.Contains(prefix, ":") || strings.Contains(prefix, "*") {
+		panic("URL parameters can not be used when mounting a handler")
+	}
+	absolutePath := strings.TrimSuffix(joinPaths(r.prefix, prefix), "/")
+	handler := WrapH(http.StripPrefix(absolutePath, h))
+	r.Any(prefix, handler)
+	return r.Any(joinPaths(prefix, "/**"), handler)
+}
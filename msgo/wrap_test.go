@@ -0,0 +1,58 @@
+package msgo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+type stdKey struct{}
+
+func TestMiddlewareFromStd(t *testing.T) {
+	engine := New()
+	engine.Use(MiddlewareFromStd(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("deny") != "" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Header().Set("X-Std", "1")
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), stdKey{}, "std")))
+		})
+	}))
+	g := engine.Group("user")
+	g.Get("/info", func(ctx *Context) {
+		ctx.String(http.StatusOK, ctx.R.Context().Value(stdKey{}).(string))
+	})
+	g.Get("/std", WrapF(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("wrapped"))
+	}))
+
+	w := performRequest(engine, http.MethodGet, "/user/info")
+	if w.Code != http.StatusOK || w.Body.String() != "std" || w.Header().Get("X-Std") != "1" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	w = performRequest(engine, http.MethodGet, "/user/info?deny=1")
+	if w.Code != http.StatusForbidden || w.Body.String() != "" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	w = performRequest(engine, http.MethodGet, "/user/std")
+	if w.Body.String() != "wrapped" {
+		t.Fatalf("got %q", w.Body.String())
+	}
+}
+
+func TestMount(t *testing.T) {
+	engine := New()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello " + r.URL.Path))
+	})
+	api := engine.Group("api")
+	api.Mount("/legacy", mux)
+
+	w := performRequest(engine, http.MethodPost, "/api/legacy/hello")
+	if w.Code != http.StatusOK || w.Body.String() != "hello /hello" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+}
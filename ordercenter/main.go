@@ -1,7 +1,6 @@
 package main
 
 import (
-	"context"
 	"encoding/gob"
 	"encoding/json"
 	"github.com/mszlu521/msgo"
@@ -38,8 +37,10 @@ func main() {
 	)
 	if err != nil {
 		log.Println(err)
+	} else {
+		//退出时刷新tracer
+		engine.OnShutdown(closer.Close)
 	}
-	defer closer.Close()
 
 	group.Get("/find", func(ctx *msgo.Context) {
 		//通过商品中心 查询商品的信息
@@ -74,7 +75,7 @@ func main() {
 		client, _ := rpc.NewGrpcClient(config)
 		defer client.Conn.Close()
 		goodsApiClient := api.NewGoodsApiClient(client.Conn)
-		goodsResponse, _ := goodsApiClient.Find(context.Background(), &api.GoodsRequest{})
+		goodsResponse, _ := goodsApiClient.Find(ctx, &api.GoodsRequest{})
 		ctx.JSON(http.StatusOK, goodsResponse)
 	})
 
@@ -92,7 +93,7 @@ func main() {
 		params := make([]any, 1)
 		params[0] = int64(1)
 		//var Find func(id int64) any 作业
-		result, err := proxy.Call(context.Background(), "goods", "Find", params)
+		result, err := proxy.Call(ctx, "goods", "Find", params)
 		//Find(1)
 		log.Println(err)
 		ctx.JSON(http.StatusOK, result)